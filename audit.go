@@ -0,0 +1,55 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// AuditSink is a function receiving the exact bytes of the request body sent to the server
+// and of the response body responded by the server, e.g. to record them durably for compliance.
+// The bytes are copies owned by the sink.
+type AuditSink func(ctx context.Context, method string, requestBytes, responseBytes []byte)
+
+// WithAuditSink returns an Option that calls sink when the call succeeds, before the call returns.
+// If the call is retried, the bytes are of the last attempt.
+// It is used only for Call and the methods built on it.
+func WithAuditSink(sink AuditSink) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.AuditSink = sink
+	})
+}
+
+// WithAuditFailures returns an Option that calls the sink given by WithAuditSink also when the call fails.
+// The response bytes are nil if no response is read.
+func WithAuditFailures() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.AuditFailures = true
+	})
+}
+
+// auditBody reads the whole body into the scratch for the audit sink, and returns a reader of the read body.
+func (opts *callOptions) auditBody(body io.Reader, scratch *callScratch) (io.Reader, error) {
+	if opts.AuditSink == nil {
+		return body, nil
+	}
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	scratch.audit = b
+
+	return bytes.NewReader(b), nil
+}
+
+// audit calls the audit sink with the request body and the response body read into the scratch.
+func (opts *callOptions) audit(ctx context.Context, body []byte, scratch *callScratch, err error) {
+	if opts.AuditSink == nil || (err != nil && !opts.AuditFailures) {
+		return
+	}
+
+	opts.AuditSink(ctx, opts.method, append([]byte(nil), body...), scratch.audit)
+}
@@ -0,0 +1,69 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCallWithAuditSink(t *testing.T) {
+	var wireReq, wireRes []byte
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "fail" {
+			return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+		}
+		return "ok", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wireReq, _ = ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(wireReq))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		wireRes = rec.Body.Bytes()
+		w.Write(wireRes)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	type audit struct {
+		method   string
+		req, res []byte
+	}
+	var audits []audit
+	sink := WithAuditSink(func(ctx context.Context, method string, requestBytes, responseBytes []byte) {
+		audits = append(audits, audit{method, requestBytes, responseBytes})
+	})
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "mutate", map[string]int{"n": 1}, &result, sink); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if len(audits) != 1 {
+		t.Fatalf("sink got %d audits, want 1", len(audits))
+	}
+	if a := audits[0]; a.method != "mutate" || !bytes.Equal(a.req, wireReq) || !bytes.Equal(a.res, wireRes) {
+		t.Errorf("sink got %s %s %s, want mutate %s %s", a.method, a.req, a.res, wireReq, wireRes)
+	}
+
+	if err := client.Call(context.Background(), server.URL, "fail", nil, &result, sink); err == nil {
+		t.Fatal("Client.Call() must fail")
+	}
+	if len(audits) != 1 {
+		t.Fatalf("sink got %d audits for the failed call without WithAuditFailures, want 1", len(audits))
+	}
+
+	if err := client.Call(context.Background(), server.URL, "fail", nil, &result, sink, WithAuditFailures()); err == nil {
+		t.Fatal("Client.Call() must fail")
+	}
+	if len(audits) != 2 {
+		t.Fatalf("sink got %d audits, want 2", len(audits))
+	}
+	if a := audits[1]; a.method != "fail" || !bytes.Equal(a.req, wireReq) || !bytes.Equal(a.res, wireRes) {
+		t.Errorf("sink got %s %s %s, want fail %s %s", a.method, a.req, a.res, wireReq, wireRes)
+	}
+}
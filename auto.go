@@ -0,0 +1,70 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// RegisterResultType registers the type of proto as the result type of the method for CallAuto.
+// If proto is a pointer, the type it points to is registered, so a nil pointer of the type can be passed.
+// It panics if proto is nil, as no type can be registered for it.
+func (client *Client) RegisterResultType(method string, proto interface{}) {
+	t := reflect.TypeOf(proto)
+	if t == nil {
+		panic(fmt.Sprintf("jsonrpc: RegisterResultType got a nil proto for method %q", method))
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	client.resultTypesMu.Lock()
+	defer client.resultTypesMu.Unlock()
+
+	if client.resultTypes == nil {
+		client.resultTypes = make(map[string]reflect.Type)
+	}
+	client.resultTypes[method] = t
+}
+
+func (client *Client) resultType(method string) (reflect.Type, bool) {
+	client.resultTypesMu.RLock()
+	defer client.resultTypesMu.RUnlock()
+
+	t, ok := client.resultTypes[method]
+	return t, ok
+}
+
+// CallAuto calls the method on the url with the params,
+// and returns the result decoded into a newly allocated value of the type registered by RegisterResultType.
+// The result is a pointer to the value of the registered type.
+// If no type is registered for the method, the result is decoded into map[string]interface{}.
+func (client *Client) CallAuto(ctx context.Context, url string, method string, params interface{}, opts ...Option) (interface{}, error) {
+	t, ok := client.resultType(method)
+	if !ok {
+		var result map[string]interface{}
+		if err := client.Call(ctx, url, method, params, &result, opts...); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result := reflect.New(t).Interface()
+	if err := client.Call(ctx, url, method, params, result, opts...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// WithMethodResultType returns a ClientOption that registers the type of proto
+// as the result type of the method for CallAuto, same as RegisterResultType.
+// NewClient panics if proto is nil.
+func WithMethodResultType(method string, proto interface{}) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		if opts.ResultTypes == nil {
+			opts.ResultTypes = make(map[string]interface{})
+		}
+		opts.ResultTypes[method] = proto
+	})
+}
@@ -0,0 +1,112 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAutoUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestClientCallAuto(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return map[string]interface{}{"id": 1, "name": "test"}, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+	client.RegisterResultType("user.get", &testAutoUser{})
+
+	result, err := client.CallAuto(context.Background(), server.URL, "user.get", nil)
+	if err != nil {
+		t.Fatalf("Client.CallAuto() failed: %v", err)
+	}
+	user, ok := result.(*testAutoUser)
+	if !ok {
+		t.Fatalf("Client.CallAuto() got %T, want *testAutoUser", result)
+	}
+	if want := (&testAutoUser{ID: 1, Name: "test"}); !reflect.DeepEqual(user, want) {
+		t.Errorf("Client.CallAuto() got %+v, want %+v", user, want)
+	}
+
+	result, err = client.CallAuto(context.Background(), server.URL, "user.unregistered", nil)
+	if err != nil {
+		t.Fatalf("Client.CallAuto() failed: %v", err)
+	}
+	m, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Client.CallAuto() got %T, want map[string]interface{}", result)
+	}
+	if m["name"] != "test" {
+		t.Errorf("Client.CallAuto() got %v, want name test", m)
+	}
+}
+
+func TestClientCallAutoWithMethodResultType(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return map[string]interface{}{"id": 2, "name": "option"}, nil
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMethodResultType("user.get", testAutoUser{}))
+
+	result, err := client.CallAuto(context.Background(), server.URL, "user.get", nil)
+	if err != nil {
+		t.Fatalf("Client.CallAuto() failed: %v", err)
+	}
+	user, ok := result.(*testAutoUser)
+	if !ok {
+		t.Fatalf("Client.CallAuto() got %T, want *testAutoUser", result)
+	}
+	if want := (&testAutoUser{ID: 2, Name: "option"}); !reflect.DeepEqual(user, want) {
+		t.Errorf("Client.CallAuto() got %+v, want %+v", user, want)
+	}
+}
+
+func TestClientRegisterResultTypeNil(t *testing.T) {
+	tests := map[string]struct {
+		register  func()
+		wantPanic bool
+	}{
+		"nil": {
+			register: func() {
+				(&Client{}).RegisterResultType("user.get", nil)
+			},
+			wantPanic: true,
+		},
+		"nil pointer": {
+			register: func() {
+				(&Client{}).RegisterResultType("user.get", (*testAutoUser)(nil))
+			},
+		},
+		"option": {
+			register: func() {
+				NewClient(WithMethodResultType("user.get", nil))
+			},
+			wantPanic: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if tt.wantPanic && r == nil {
+					t.Error("Client.RegisterResultType() must panic")
+				}
+				if !tt.wantPanic && r != nil {
+					t.Errorf("Client.RegisterResultType() panics: %v", r)
+				}
+				if msg, _ := r.(string); tt.wantPanic && !strings.Contains(msg, `"user.get"`) {
+					t.Errorf("Client.RegisterResultType() panics with %v, want the method name", r)
+				}
+			}()
+			tt.register()
+		})
+	}
+}
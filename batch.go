@@ -0,0 +1,875 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// BatchRequest represents a request in a batch call.
+type BatchRequest struct {
+	// Method is a method name to call.
+	Method string
+	// Params is params of the method.
+	Params interface{}
+	// Result is a value the result responded by the server is stored in.
+	// The result is not decoded if Result is nil.
+	Result interface{}
+	// New returns a new value the result is stored in, used by CallBatchValues if Result is nil.
+	New func() interface{}
+	// Validate validates Params before the batch is sent, if it is not nil.
+	// The batch is not sent if any of the requests is invalid.
+	Validate func(params interface{}) error
+	// Unmarshal decodes the result into Result instead of the unmarshaler of the call, if it is not nil.
+	// e.g. to decode only one result of the batch with protojson.
+	Unmarshal func(raw json.RawMessage, result interface{}) error
+	// Priority is the priority of the request in the batch.
+	// The requests are sent in the descending order of Priority, and in the given order for the same Priority,
+	// so that the servers processing a batch sequentially process the important ones first
+	// in case the batch does not complete before the deadline.
+	// The responses are still returned in the given order.
+	Priority int
+	// Notification sends the request as a notification, which has no id.
+	// The server does not respond to a notification,
+	// so its BatchResponse is always zero and Result is not used.
+	Notification bool
+}
+
+// decodeResult decodes raw into the Result of req by its Unmarshal if it is set,
+// otherwise as the result of the call.
+func (req *BatchRequest) decodeResult(raw json.RawMessage, opts callOptions) error {
+	if req.Unmarshal != nil {
+		opts.ResultUnmarshaler = req.Unmarshal
+	}
+
+	return decodeResult(raw, req.Result, opts)
+}
+
+// BatchResponse represents a response to a request in a batch call.
+type BatchResponse struct {
+	// Result is the raw result responded by the server.
+	Result json.RawMessage
+	// Error is an error responded by the server.
+	// It is nil if the request succeeded.
+	Error *ResponseError
+	// Pages is the raw results of all the pages of the request, including Result as the first page,
+	// if the pages are followed by WithBatchPagination.
+	Pages []json.RawMessage
+}
+
+// batchRequestBody returns the ids of reqs and the body of the batch request.
+// The id of a notification is the zero value.
+func batchRequestBody(reqs []BatchRequest, opts callOptions) ([]requestID, []byte, error) {
+	var sent map[string]bool
+	if opts.NotificationDedup {
+		sent = make(map[string]bool)
+	}
+
+	ids := make([]requestID, len(reqs))
+	rs := make([]interface{}, 0, len(reqs))
+	for _, i := range priorityOrder(reqs) {
+		req := reqs[i]
+		if req.Method == "" {
+			return nil, nil, fmt.Errorf("method of request %d is empty", i)
+		}
+
+		if req.Validate != nil {
+			if err := req.Validate(req.Params); err != nil {
+				return nil, nil, fmt.Errorf("request %d (%s): invalid params: %w", i, req.Method, err)
+			}
+		}
+
+		params, err := opts.marshalParams(req.Method, req.Params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("request %d (%s): %w", i, req.Method, err)
+		}
+		req.Params = params
+
+		if req.Notification {
+			if sent != nil {
+				key, err := notificationKey(req)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to marshal params of request %d: %w", i, err)
+				}
+				if sent[key] {
+					continue
+				}
+				sent[key] = true
+			}
+
+			rs = append(rs, opts.EnvelopeKeyCase.notificationEnvelope(&notification{
+				JSONRPC: Version,
+				Method:  req.Method,
+				Params:  req.Params,
+			}))
+			continue
+		}
+
+		id, err := opts.newID()
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[i] = id
+		rs = append(rs, opts.EnvelopeKeyCase.envelope(&request{
+			JSONRPC: Version,
+			Method:  req.Method,
+			Params:  req.Params,
+			ID:      ids[i],
+		}))
+	}
+
+	b, err := json.Marshal(rs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal batch request: %w", err)
+	}
+
+	return ids, b, nil
+}
+
+// priorityOrder returns the indexes of reqs in the descending order of their priorities.
+func priorityOrder(reqs []BatchRequest) []int {
+	order := make([]int, len(reqs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return reqs[order[i]].Priority > reqs[order[j]].Priority
+	})
+
+	return order
+}
+
+// notificationKey returns a key identifying the notification by its method and marshaled params.
+func notificationKey(req BatchRequest) (string, error) {
+	b, err := json.Marshal(req.Params)
+	if err != nil {
+		return "", err
+	}
+
+	return req.Method + "\x00" + string(b), nil
+}
+
+// batchIndexes returns the indexes of the requests by their ids, except notifications.
+func batchIndexes(reqs []BatchRequest, ids []requestID) map[requestID]int {
+	indexes := make(map[requestID]int, len(ids))
+	for i, id := range ids {
+		if !reqs[i].Notification {
+			indexes[id] = i
+		}
+	}
+
+	return indexes
+}
+
+// BatchResult is the result of a batch call.
+type BatchResult struct {
+	// Responses are the responses in the same order as the requests.
+	Responses []BatchResponse
+	// StatusCode is the HTTP status code of the response, e.g. 200.
+	StatusCode int
+	// Header is the header of the HTTP response.
+	Header http.Header
+	// Trailer is the trailer of the HTTP response, if the server sends it.
+	Trailer http.Header
+}
+
+// CallBatch calls the methods of reqs on the url in a single batch request.
+// The responses are returned in the same order as reqs with the metadata of the HTTP response,
+// and each result is stored in the Result of the corresponding request.
+//
+// The requests share a single HTTP request, so the transport-level options, e.g. headers,
+// are applied to the whole batch. The validation of params and the decoding of results can be given
+// to each request by Validate and Unmarshal of BatchRequest.
+//
+// A response with an id not sent in the batch fails the call with a ProtocolError of UnknownID,
+// and more than one response to the same id with a ProtocolError of DuplicateID,
+// while an error response with a null id is ignored as the error to a request whose id cannot be determined.
+// A successful response with a null id fails the call with a ProtocolError of NullID.
+func (client *Client) CallBatch(ctx context.Context, url string, reqs []BatchRequest, opts ...Option) (*BatchResult, error) {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	callOpts := newCallOptions(opts)
+
+	ctx, cancel := client.withTimeout(ctx, url, callOpts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	var result *BatchResult
+	err := client.withCircuit(url, func() error {
+		var err error
+		result, err = client.callBatch(ctx, url, reqs, opts)
+		if err == nil && callOpts.BatchPagination != nil {
+			err = client.paginateBatch(ctx, url, reqs, result, callOpts.BatchPagination, opts)
+		}
+		return contextError(ctx, err)
+	})
+	client.metrics.recordCall(err)
+	callOpts.runHooks(ctx, CallInfo{
+		BatchSize: len(reqs),
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (client *Client) callBatch(ctx context.Context, url string, reqs []BatchRequest, opts []Option) (_ *BatchResult, err error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("batch is empty")
+	}
+
+	callOpts := newCallOptions(opts)
+	callOpts.batchSize = len(reqs)
+
+	if err := callOpts.validateGETBatch(reqs); err != nil {
+		return nil, err
+	}
+	if err := client.checkBatchSupported(url); err != nil {
+		return nil, err
+	}
+
+	ids, body, err := batchRequestBody(reqs, callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	persisted := callOpts.persistBatch(ids)
+	defer func() { persisted(err) }()
+
+	indexes := batchIndexes(reqs, ids)
+	if len(indexes) == 0 {
+		res, err := client.sendNotification(ctx, url, body, callOpts)
+		if err != nil {
+			return nil, err
+		}
+		return newBatchResult(make([]BatchResponse, len(reqs)), res), nil
+	}
+
+	res, err := client.post(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(res.Body)
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&raw); err != nil {
+		if perr := checkEmptyBody(err); perr != nil {
+			return nil, perr
+		}
+		return nil, fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+	// read the rest of the body to receive the trailer.
+	closeBody(res.Body)
+
+	var rpcResList []*response
+	if raw = bytes.TrimSpace(raw); callOpts.ObjectBatchResponse && len(raw) > 0 && raw[0] == '{' {
+		rpcResList, err = decodeObjectBatch(raw)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(raw) > 0 && raw[0] == '{' {
+		var rpcRes response
+		if err := json.Unmarshal(raw, &rpcRes); err != nil {
+			return nil, fmt.Errorf("failed to decode response JSON: %w", err)
+		}
+
+		_, matched, _ := rpcRes.index(indexes)
+		switch {
+		case len(indexes) == 1 && matched:
+			// some servers respond a single object to a one-request batch.
+			rpcResList = []*response{&rpcRes}
+		case rpcRes.Error != nil:
+			// the server responds a single response if the batch itself is invalid.
+			callOpts.mapErrorCode(rpcRes.Error)
+			client.decodeErrorData(rpcRes.Error)
+			return nil, rpcRes.Error
+		default:
+			return nil, errors.New("server does not respond an array to the batch request")
+		}
+	} else if err := json.Unmarshal(raw, &rpcResList); err != nil {
+		return nil, fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+
+	resps := make([]BatchResponse, len(reqs))
+	found := make([]bool, len(reqs))
+	for _, rpcRes := range rpcResList {
+		i, ok, err := rpcRes.index(indexes)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if found[i] {
+			return nil, duplicateID(string(rpcRes.ID))
+		}
+		if err := checkVersion(rpcRes.JSONRPC); err != nil {
+			return nil, err
+		}
+
+		callOpts.mapErrorCode(rpcRes.Error)
+		client.decodeErrorData(rpcRes.Error)
+		resps[i] = BatchResponse{
+			Result: rpcRes.Result,
+			Error:  rpcRes.Error,
+		}
+		found[i] = true
+	}
+
+	for i, req := range reqs {
+		if req.Notification {
+			continue
+		}
+		if !found[i] {
+			return nil, fmt.Errorf("response to request %d (%s) is missing", i, req.Method)
+		}
+
+		if req.Result == nil || resps[i].Error != nil {
+			continue
+		}
+
+		if err := req.decodeResult(resps[i].Result, callOpts); err != nil {
+			return nil, fmt.Errorf("request %d (%s): %w", i, req.Method, err)
+		}
+	}
+
+	return newBatchResult(resps, res), nil
+}
+
+// index returns the index of the request to the response by its id in indexes.
+// It returns false for an error with a null id, which is the error to a request whose id cannot be determined,
+// and fails with a ProtocolError if the id is not sent in the batch, or a successful result has a null id.
+func (rpcRes *response) index(indexes map[requestID]int) (int, bool, error) {
+	if isNullJSON(rpcRes.ID) {
+		if rpcRes.Error == nil {
+			return 0, false, &ProtocolError{
+				Kind:    NullID,
+				Message: "server responds a successful result with a null id",
+			}
+		}
+		return 0, false, nil
+	}
+
+	if id, err := parseRequestID(rpcRes.ID); err == nil {
+		if i, ok := indexes[id]; ok {
+			return i, true, nil
+		}
+	}
+
+	return 0, false, unknownID(string(rpcRes.ID))
+}
+
+// newBatchResult returns a BatchResult of the responses with the metadata of res.
+// The body of res must be read before it to receive the trailer.
+func newBatchResult(resps []BatchResponse, res *http.Response) *BatchResult {
+	return &BatchResult{
+		Responses:  resps,
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Trailer:    res.Trailer,
+	}
+}
+
+// RawResponse represents a response to a pre-marshaled request entry in BatchCallRawEntries.
+type RawResponse struct {
+	// ID is the raw id of the response, which matches the id of the entry.
+	ID json.RawMessage
+	// Result is the raw result responded by the server.
+	Result json.RawMessage
+	// Error is an error responded by the server.
+	// It is nil if the request succeeded.
+	Error *ResponseError
+}
+
+// BatchCallRawEntries sends entries, which are pre-marshaled request objects, to the url in a single batch request.
+// The entries are written into the batch array as is, without decoding or encoding them,
+// so it is the lowest-allocation batch path for extremely large batches.
+//
+// The responses are matched to the entries by the id parsed from each entry,
+// and returned in the same order as entries.
+// The response to an entry without id, i.e. a notification, is zero.
+// A response with an id not sent fails with UnknownID, and more than one response to the same id with DuplicateID.
+func (client *Client) BatchCallRawEntries(ctx context.Context, url string, entries []json.RawMessage, opts ...Option) ([]RawResponse, error) {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	resps, err := client.batchCallRawEntries(ctx, url, entries, opts)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return resps, nil
+}
+
+func (client *Client) batchCallRawEntries(ctx context.Context, url string, entries []json.RawMessage, opts []Option) ([]RawResponse, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("batch is empty")
+	}
+
+	callOpts := newCallOptions(opts)
+	callOpts.batchSize = len(entries)
+
+	if err := client.checkBatchSupported(url); err != nil {
+		return nil, err
+	}
+
+	size := len(entries) + 1
+	indexes := make(map[string]int, len(entries))
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		size += len(entry)
+
+		var e struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal(entry, &e); err != nil {
+			return nil, fmt.Errorf("failed to decode entry %d: %w", i, err)
+		}
+		if isNullJSON(e.ID) {
+			continue
+		}
+
+		key, err := rawIDKey(e.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode id of entry %d: %w", i, err)
+		}
+		if _, ok := indexes[key]; ok {
+			return nil, fmt.Errorf("id of entry %d is duplicated: %s", i, key)
+		}
+		indexes[key] = i
+		keys[i] = key
+	}
+
+	body := make([]byte, 0, size)
+	body = append(body, '[')
+	for i, entry := range entries {
+		if i > 0 {
+			body = append(body, ',')
+		}
+		body = append(body, entry...)
+	}
+	body = append(body, ']')
+
+	if len(indexes) == 0 {
+		if _, err := client.sendNotification(ctx, url, body, callOpts); err != nil {
+			return nil, err
+		}
+		return make([]RawResponse, len(entries)), nil
+	}
+
+	res, err := client.post(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(res.Body)
+
+	var rpcResList []RawResponse
+	if err := json.NewDecoder(res.Body).Decode(&rpcResList); err != nil {
+		if perr := checkEmptyBody(err); perr != nil {
+			return nil, perr
+		}
+		return nil, fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+
+	resps := make([]RawResponse, len(entries))
+	found := make([]bool, len(entries))
+	for _, rpcRes := range rpcResList {
+		if isNullJSON(rpcRes.ID) {
+			continue
+		}
+		key, err := rawIDKey(rpcRes.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response id: %w", err)
+		}
+
+		i, ok := indexes[key]
+		if !ok {
+			return nil, unknownID(string(rpcRes.ID))
+		}
+		if found[i] {
+			return nil, duplicateID(string(rpcRes.ID))
+		}
+		callOpts.mapErrorCode(rpcRes.Error)
+		client.decodeErrorData(rpcRes.Error)
+		resps[i] = rpcRes
+		found[i] = true
+	}
+
+	for i, key := range keys {
+		if key != "" && !found[i] {
+			return nil, fmt.Errorf("response to entry %d (id %s) is missing", i, key)
+		}
+	}
+
+	return resps, nil
+}
+
+// rawIDKey returns the compacted form of the raw id to match the ids of requests and responses.
+func rawIDKey(id json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, id); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// BatchForEach returns batch requests calling the same method for each element of args,
+// with the element as params and the element of results at the same index as the result.
+// If results is nil, the results are not decoded.
+// It panics if results is not nil and its length differs from args.
+func BatchForEach[T, R any](method string, args []T, results []*R) []BatchRequest {
+	if results != nil && len(results) != len(args) {
+		panic(fmt.Sprintf("jsonrpc: BatchForEach got %d results for %d args", len(results), len(args)))
+	}
+
+	reqs := make([]BatchRequest, len(args))
+	for i, arg := range args {
+		reqs[i] = BatchRequest{
+			Method: method,
+			Params: arg,
+		}
+		if results != nil {
+			reqs[i].Result = results[i]
+		}
+	}
+
+	return reqs
+}
+
+// CallBatchValues calls the methods of reqs on the url in a single batch request like CallBatch,
+// and returns the results stored in the values allocated by New of each request,
+// so that the results of different types can be decoded without declaring them in advance.
+// The value is Result of the request if it is not nil,
+// and nil for the request failed with an error, or without both Result and New.
+// reqs is not modified.
+func (client *Client) CallBatchValues(ctx context.Context, url string, reqs []BatchRequest, opts ...Option) ([]interface{}, *BatchResult, error) {
+	reqs = append([]BatchRequest(nil), reqs...)
+	for i, req := range reqs {
+		if req.Result == nil && req.New != nil && !req.Notification {
+			reqs[i].Result = req.New()
+		}
+	}
+
+	res, err := client.CallBatch(ctx, url, reqs, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]interface{}, len(reqs))
+	for i, req := range reqs {
+		if res.Responses[i].Error == nil {
+			values[i] = req.Result
+		}
+	}
+
+	return values, res, nil
+}
+
+// BatchDecode decodes the results of resps into a slice of T.
+// It returns the results and the errors aligned to resps.
+// The error is nil where the request succeeded and the result is decoded.
+func BatchDecode[T any](resps []BatchResponse) ([]T, []error) {
+	results := make([]T, len(resps))
+	errs := make([]error, len(resps))
+	for i, resp := range resps {
+		if resp.Error != nil {
+			errs[i] = resp.Error
+			continue
+		}
+
+		if err := json.Unmarshal(resp.Result, &results[i]); err != nil {
+			errs[i] = fmt.Errorf("failed to decode result JSON: %w", err)
+		}
+	}
+
+	return results, errs
+}
+
+// CallBatchStream calls the methods of reqs on the url in a single batch request,
+// and calls fn with each response and the request it is correlated to,
+// as soon as the response is decoded from the response body.
+// The result is stored in the Result of the request before fn is called.
+// CallBatchStream stops reading the responses if fn returns an error, and returns the error.
+// The responses are validated like CallBatch, e.g. more than one response to the same id fails with DuplicateID.
+func (client *Client) CallBatchStream(ctx context.Context, url string, reqs []BatchRequest, fn func(req BatchRequest, resp BatchResponse) error, opts ...Option) error {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	callOpts := newCallOptions(opts)
+
+	start := time.Now()
+	err := contextError(ctx, client.callBatchStream(ctx, url, reqs, func(i int, resp BatchResponse) error {
+		return fn(reqs[i], resp)
+	}, opts))
+	callOpts.runHooks(ctx, CallInfo{
+		BatchSize: len(reqs),
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+
+	return err
+}
+
+// callBatchStream calls fn with the index of the request and its response as soon as the response is decoded.
+func (client *Client) callBatchStream(ctx context.Context, url string, reqs []BatchRequest, fn func(i int, resp BatchResponse) error, opts []Option) error {
+	if len(reqs) == 0 {
+		return errors.New("batch is empty")
+	}
+
+	callOpts := newCallOptions(opts)
+	callOpts.batchSize = len(reqs)
+
+	if err := callOpts.validateGETBatch(reqs); err != nil {
+		return err
+	}
+	if err := client.checkBatchSupported(url); err != nil {
+		return err
+	}
+
+	ids, body, err := batchRequestBody(reqs, callOpts)
+	if err != nil {
+		return err
+	}
+
+	indexes := batchIndexes(reqs, ids)
+	if len(indexes) == 0 {
+		_, err := client.sendNotification(ctx, url, body, callOpts)
+		return err
+	}
+
+	res, err := client.post(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		return err
+	}
+	defer closeBody(res.Body)
+
+	dec := json.NewDecoder(res.Body)
+	tok, err := dec.Token()
+	if err != nil {
+		if perr := checkEmptyBody(err); perr != nil {
+			return perr
+		}
+		return fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+	if tok != json.Delim('[') {
+		return errors.New("server does not respond an array to the batch request")
+	}
+
+	found := make([]bool, len(reqs))
+	items := 0
+	for dec.More() {
+		var rpcRes response
+		if err := dec.Decode(&rpcRes); err != nil {
+			return incompleteResult(items, fmt.Errorf("failed to decode response JSON: %w", err))
+		}
+		items++
+
+		i, ok, err := rpcRes.index(indexes)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if found[i] {
+			return duplicateID(string(rpcRes.ID))
+		}
+		if err := checkVersion(rpcRes.JSONRPC); err != nil {
+			return err
+		}
+		found[i] = true
+		callOpts.mapErrorCode(rpcRes.Error)
+		client.decodeErrorData(rpcRes.Error)
+
+		req := reqs[i]
+		if req.Result != nil && rpcRes.Error == nil {
+			if err := req.decodeResult(rpcRes.Result, callOpts); err != nil {
+				return fmt.Errorf("request %d (%s): %w", i, req.Method, err)
+			}
+		}
+
+		if err := fn(i, BatchResponse{
+			Result: rpcRes.Result,
+			Error:  rpcRes.Error,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return incompleteResult(items, err)
+	}
+
+	for i, req := range reqs {
+		if !found[i] && !req.Notification {
+			return fmt.Errorf("response to request %d (%s) is missing", i, req.Method)
+		}
+	}
+
+	return nil
+}
+
+// PartialBatchError is returned by CallBatchPartial when the context is done
+// before all the responses to the batch are read.
+type PartialBatchError struct {
+	// Unanswered is the indexes of the requests whose responses are not read, except notifications.
+	Unanswered []int
+	// Err is the error of the context.
+	Err error
+}
+
+func (err *PartialBatchError) Error() string {
+	return fmt.Sprintf("batch is partially answered, %d requests unanswered: %v", len(err.Unanswered), err.Err)
+}
+
+func (err *PartialBatchError) Unwrap() error {
+	return err.Err
+}
+
+// CallBatchPartial calls the methods of reqs on the url in a single batch request like CallBatchStream,
+// and returns the responses in the same order as reqs.
+// If the context is done before all the responses are read, e.g. the deadline is exceeded mid-response,
+// it returns the responses read so far with a *PartialBatchError of the unanswered requests,
+// so that a best-effort batch can use the results completed before the deadline.
+// The responses of the unanswered requests are zero.
+func (client *Client) CallBatchPartial(ctx context.Context, url string, reqs []BatchRequest, opts ...Option) (_ []BatchResponse, err error) {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	callOpts := newCallOptions(opts)
+
+	start := time.Now()
+	defer func() {
+		callOpts.runHooks(ctx, CallInfo{
+			BatchSize: len(reqs),
+			Duration:  time.Since(start),
+			Err:       err,
+		})
+	}()
+
+	resps := make([]BatchResponse, len(reqs))
+	answered := make([]bool, len(reqs))
+	err = client.callBatchStream(ctx, url, reqs, func(i int, resp BatchResponse) error {
+		resps[i] = resp
+		answered[i] = true
+		return nil
+	}, opts)
+	if err != nil && ctx.Err() != nil {
+		var unanswered []int
+		for i, req := range reqs {
+			if !answered[i] && !req.Notification {
+				unanswered = append(unanswered, i)
+			}
+		}
+		return resps, &PartialBatchError{
+			Unanswered: unanswered,
+			Err:        contextError(ctx, ctx.Err()),
+		}
+	}
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return resps, nil
+}
+
+// WithNotificationDedup returns an Option that drops duplicate notifications in a batch,
+// which have the same method and the same marshaled params, before sending the batch.
+func WithNotificationDedup() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.NotificationDedup = true
+	})
+}
+
+// WithGETBatch returns an Option that sends the batch by GET with the batch encoded
+// into the batch query parameter, so that caching proxies and CDNs can cache batched reads.
+// It is used only for batch calls, and only valid for a batch of idempotent methods.
+// A batch containing notifications cannot be sent by GET.
+func WithGETBatch() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.GETBatch = true
+	})
+}
+
+// WithObjectBatchResponse returns an Option that accepts the non-standard batch response
+// of a single object mapping the id of each request to its response, e.g.
+//
+//	{"<id>": {"result": 1}, "<id>": {"error": {"code": -32603, "message": "internal error"}}}
+//
+// instead of an array. The response of each id is routed to the request of the id.
+// The jsonrpc member may be omitted in the responses of the object.
+// A batch response of an array is still accepted. It is not applied to CallBatchStream.
+func WithObjectBatchResponse() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ObjectBatchResponse = true
+	})
+}
+
+// decodeObjectBatch decodes the batch response of an object keyed by the ids of the requests.
+func decodeObjectBatch(raw json.RawMessage) ([]*response, error) {
+	var obj map[string]*response
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+
+	rpcResList := make([]*response, 0, len(obj))
+	for key, rpcRes := range obj {
+		if rpcRes == nil {
+			continue
+		}
+		// the key is matched as a string id, so that a key not sent fails as UnknownID.
+		rpcRes.ID, _ = json.Marshal(key)
+		if rpcRes.JSONRPC == "" {
+			rpcRes.JSONRPC = Version
+		}
+		rpcResList = append(rpcResList, rpcRes)
+	}
+
+	return rpcResList, nil
+}
+
+// validateGETBatch returns an error if the batch is sent by GET and reqs contains a notification.
+func (opts *callOptions) validateGETBatch(reqs []BatchRequest) error {
+	if !opts.GETBatch {
+		return nil
+	}
+
+	for i, req := range reqs {
+		if req.Notification {
+			return fmt.Errorf("request %d (%s) is a notification, which cannot be sent in a GET batch", i, req.Method)
+		}
+	}
+
+	return nil
+}
+
+// newGETBatchRequest returns a GET request to the endpoint with the batch read from body
+// encoded into the batch query parameter.
+func newGETBatchRequest(ctx context.Context, endpoint string, body io.Reader) (*http.Request, error) {
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("batch", string(b))
+	u.RawQuery = q.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+}
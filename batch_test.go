@@ -0,0 +1,1089 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testBatchServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(testBatchHandler(t))
+}
+
+func testBatchHandler(t *testing.T) http.Handler {
+	return rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		switch req.Method {
+		case "echo":
+			var v interface{}
+			json.Unmarshal(req.Params, &v)
+			return v, nil
+		case "fail":
+			return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+		}
+		return nil, &ResponseError{Code: MethodNotFound, Message: "method not found"}
+	})
+}
+
+func TestClientCallBatch(t *testing.T) {
+	server := testBatchServer(t)
+	defer server.Close()
+
+	client := &Client{}
+
+	var first int
+	var second string
+	res, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: []int{1}, Result: &[]int{}},
+		{Method: "echo", Params: 1, Result: &first},
+		{Method: "fail", Result: &second},
+		{Method: "echo", Params: "second", Result: &second},
+	})
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	resps := res.Responses
+
+	if len(resps) != 4 {
+		t.Fatalf("Client.CallBatch() got %d responses, want 4", len(resps))
+	}
+	if first != 1 {
+		t.Errorf("result of request 1 got %d, want 1", first)
+	}
+	if resps[2].Error == nil || resps[2].Error.Code != InternalError {
+		t.Errorf("error of request 2 got %v, want InternalError", resps[2].Error)
+	}
+	if second != "second" {
+		t.Errorf("result of request 3 got %q, want %q", second, "second")
+	}
+}
+
+func TestClientCallBatchValues(t *testing.T) {
+	server := testBatchServer(t)
+	defer server.Close()
+
+	client := &Client{}
+
+	type point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+
+	reqs := []BatchRequest{
+		{Method: "echo", Params: 1, New: func() interface{} { return new(int) }},
+		{Method: "echo", Params: "two", New: func() interface{} { return new(string) }},
+		{Method: "echo", Params: point{X: 3, Y: 4}, New: func() interface{} { return new(point) }},
+		{Method: "fail", New: func() interface{} { return new(string) }},
+	}
+	values, res, err := client.CallBatchValues(context.Background(), server.URL, reqs)
+	if err != nil {
+		t.Fatalf("Client.CallBatchValues() failed: %v", err)
+	}
+
+	one, two := 1, "two"
+	want := []interface{}{&one, &two, &point{X: 3, Y: 4}, nil}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Client.CallBatchValues() got %v, want %v", values, want)
+	}
+	if res.Responses[3].Error == nil || res.Responses[3].Error.Code != InternalError {
+		t.Errorf("error of request 3 got %v, want InternalError", res.Responses[3].Error)
+	}
+	for i, req := range reqs {
+		if req.Result != nil {
+			t.Errorf("request %d is modified, Result got %v", i, req.Result)
+		}
+	}
+}
+
+func TestClientCallBatchPerRequestOptions(t *testing.T) {
+	server := testBatchServer(t)
+	defer server.Close()
+
+	client := &Client{}
+
+	// the result of the first request is decoded by its own unmarshaler, and the second one by the call.
+	var point testPoint
+	var plain map[string]int
+	reqs := []BatchRequest{
+		{
+			Method: "echo",
+			Params: map[string]int{"x": 1, "y": 2},
+			Result: &point,
+			Unmarshal: func(raw json.RawMessage, result interface{}) error {
+				var v map[string]int
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return err
+				}
+				p := result.(*testPoint)
+				p.x, p.y = v["x"], v["y"]
+				return nil
+			},
+		},
+		{
+			Method: "echo",
+			Params: map[string]int{"x": 3, "y": 4},
+			Result: &plain,
+		},
+	}
+	_, err := client.CallBatch(context.Background(), server.URL, reqs)
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	if want := (testPoint{x: 1, y: 2}); point != want {
+		t.Errorf("result of request 0 got %+v, want %+v", point, want)
+	}
+	if want := map[string]int{"x": 3, "y": 4}; !reflect.DeepEqual(plain, want) {
+		t.Errorf("result of request 1 got %v, want %v", plain, want)
+	}
+
+	errInvalid := errors.New("x must be positive")
+	reqs[1].Validate = func(params interface{}) error {
+		if params.(map[string]int)["x"] <= 0 {
+			return errInvalid
+		}
+		return nil
+	}
+	reqs[1].Params = map[string]int{"x": 0}
+	_, err = client.CallBatch(context.Background(), server.URL, reqs)
+	if !errors.Is(err, errInvalid) {
+		t.Errorf("Client.CallBatch() error got %v, want %v", err, errInvalid)
+	}
+}
+
+func TestClientCallBatchPriority(t *testing.T) {
+	var methods []string // method:params of the requests in the order received
+	handler := testBatchHandler(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var reqs []testRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			t.Errorf("failed to decode batch request: %v", err)
+		}
+		methods = methods[:0]
+		for _, req := range reqs {
+			var params string
+			json.Unmarshal(req.Params, &params)
+			methods = append(methods, req.Method+":"+params)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	results := make([]string, 4)
+	reqs := []BatchRequest{
+		{Method: "echo", Params: "low", Result: &results[0], Priority: -1},
+		{Method: "echo", Params: "normal", Result: &results[1]},
+		{Method: "echo", Params: "high", Result: &results[2], Priority: 10},
+		{Method: "fail", Result: &results[3], Priority: 10},
+	}
+	res, err := client.CallBatch(context.Background(), server.URL, reqs)
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	if want := []string{"echo:high", "fail:", "echo:normal", "echo:low"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("server got requests %v, want %v", methods, want)
+	}
+	if want := []string{"low", "normal", "high", ""}; !reflect.DeepEqual(results, want) {
+		t.Errorf("Client.CallBatch() got results %v, want %v", results, want)
+	}
+	if res.Responses[3].Error == nil {
+		t.Error("error of request 3 got nil, want InternalError")
+	}
+}
+
+func TestClientCallBatchWithObjectBatchResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []testRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode batch request: %v", err)
+		}
+		// each id maps to the response of the request.
+		obj := make(map[string]interface{})
+		for _, req := range reqs {
+			var id string
+			json.Unmarshal(req.ID, &id)
+			if req.Method == "fail" {
+				obj[id] = map[string]interface{}{"error": &ResponseError{Code: InternalError, Message: "internal error"}}
+				continue
+			}
+			obj[id] = map[string]interface{}{"result": req.Params}
+		}
+		json.NewEncoder(w).Encode(obj)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var first, second string
+	reqs := []BatchRequest{
+		{Method: "echo", Params: "first", Result: &first},
+		{Method: "fail"},
+		{Method: "echo", Params: "second", Result: &second},
+	}
+	if _, err := client.CallBatch(context.Background(), server.URL, reqs); err == nil {
+		t.Fatal("Client.CallBatch() without WithObjectBatchResponse must fail")
+	}
+
+	res, err := client.CallBatch(context.Background(), server.URL, reqs, WithObjectBatchResponse())
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	if first != "first" || second != "second" {
+		t.Errorf("Client.CallBatch() got results %q and %q, want %q and %q", first, second, "first", "second")
+	}
+	if e := res.Responses[1].Error; e == nil || e.Code != InternalError {
+		t.Errorf("error of request 1 got %v, want InternalError", e)
+	}
+}
+
+func TestClientCallBatchPartial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []testRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode batch request: %v", err)
+		}
+		// respond to the first request, and stall before the rest.
+		fmt.Fprintf(w, `[{"jsonrpc":"2.0","id":%s,"result":%s}`, reqs[0].ID, reqs[0].Params)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var first, second, third string
+	resps, err := client.CallBatchPartial(ctx, server.URL, []BatchRequest{
+		{Method: "echo", Params: "first", Result: &first},
+		{Method: "echo", Params: "second", Result: &second},
+		{Method: "notify", Notification: true},
+		{Method: "echo", Params: "third", Result: &third},
+	})
+
+	var partialErr *PartialBatchError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("Client.CallBatchPartial() error got %v, want *PartialBatchError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Client.CallBatchPartial() error got %v, want context.DeadlineExceeded", err)
+	}
+	if want := []int{1, 3}; !reflect.DeepEqual(partialErr.Unanswered, want) {
+		t.Errorf("PartialBatchError.Unanswered got %v, want %v", partialErr.Unanswered, want)
+	}
+	if len(resps) != 4 || string(resps[0].Result) != `"first"` {
+		t.Fatalf("Client.CallBatchPartial() got %v, want the response to request 0", resps)
+	}
+	if first != "first" || second != "" || third != "" {
+		t.Errorf("Client.CallBatchPartial() got results %q, %q and %q, want only the first", first, second, third)
+	}
+}
+
+func TestClientCallBatchEmpty(t *testing.T) {
+	client := &Client{}
+
+	if _, err := client.CallBatch(context.Background(), "http://example.com", nil); err == nil {
+		t.Error("Client.CallBatch() must fail with an empty batch")
+	}
+}
+
+func TestBatchDecode(t *testing.T) {
+	server := testBatchServer(t)
+	defer server.Close()
+
+	client := &Client{}
+
+	res, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: 1},
+		{Method: "fail"},
+		{Method: "echo", Params: 3},
+		{Method: "echo", Params: "not a number"},
+	})
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	resps := res.Responses
+
+	results, errs := BatchDecode[int](resps)
+	if len(results) != 4 || len(errs) != 4 {
+		t.Fatalf("BatchDecode() got %d results and %d errors, want 4 and 4", len(results), len(errs))
+	}
+
+	if results[0] != 1 || errs[0] != nil {
+		t.Errorf("BatchDecode() [0] got (%d, %v), want (1, nil)", results[0], errs[0])
+	}
+	var rpcErr *ResponseError
+	if !errors.As(errs[1], &rpcErr) {
+		t.Errorf("BatchDecode() [1] error got %v, want *ResponseError", errs[1])
+	}
+	if results[2] != 3 || errs[2] != nil {
+		t.Errorf("BatchDecode() [2] got (%d, %v), want (3, nil)", results[2], errs[2])
+	}
+	if errs[3] == nil {
+		t.Error("BatchDecode() [3] must fail to decode")
+	}
+}
+
+func TestClientCallBatchSingleRequest(t *testing.T) {
+	tests := map[string]func(w http.ResponseWriter, resp *testResponse){
+		"Object": func(w http.ResponseWriter, resp *testResponse) {
+			json.NewEncoder(w).Encode(resp)
+		},
+		"Array": func(w http.ResponseWriter, resp *testResponse) {
+			json.NewEncoder(w).Encode([]*testResponse{resp})
+		},
+	}
+	for name, respond := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reqs []*testRequest
+				if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+					t.Errorf("failed to decode batch request: %v", err)
+				}
+				respond(w, &testResponse{
+					JSONRPC: Version,
+					Result:  "single",
+					ID:      reqs[0].ID,
+				})
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			var result string
+			_, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+				{Method: "single", Result: &result},
+			})
+			if err != nil {
+				t.Fatalf("Client.CallBatch() failed: %v", err)
+			}
+			if result != "single" {
+				t.Errorf("result got %q, want %q", result, "single")
+			}
+		})
+	}
+}
+
+func TestClientCallOneElementArrayResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode([]*testResponse{{
+			JSONRPC: Version,
+			Result:  "array",
+			ID:      req.ID,
+		}})
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "array", nil, &result); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if result != "array" {
+		t.Errorf("Client.Call() result got %q, want %q", result, "array")
+	}
+}
+
+func TestClientCallBatchStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*testRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		// respond in reverse order to make sure responses are correlated by ID.
+		resps := make([]*testResponse, 0, len(reqs))
+		for i := len(reqs) - 1; i >= 0; i-- {
+			resps = append(resps, &testResponse{
+				JSONRPC: Version,
+				Result:  reqs[i].Method,
+				ID:      reqs[i].ID,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	reqs := []BatchRequest{
+		{Method: "first", Params: 1},
+		{Method: "second", Params: 2},
+		{Method: "third", Params: 3},
+	}
+
+	var got []string
+	err := client.CallBatchStream(context.Background(), server.URL, reqs, func(req BatchRequest, resp BatchResponse) error {
+		var method string
+		if err := json.Unmarshal(resp.Result, &method); err != nil {
+			return err
+		}
+		if method != req.Method {
+			t.Errorf("response %q is passed with request %q", method, req.Method)
+		}
+		got = append(got, req.Method)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Client.CallBatchStream() failed: %v", err)
+	}
+
+	if len(got) != len(reqs) {
+		t.Errorf("callback is called %d times, want %d", len(got), len(reqs))
+	}
+}
+
+func TestClientCallBatchStreamCallbackError(t *testing.T) {
+	server := testBatchServer(t)
+	defer server.Close()
+
+	client := &Client{}
+
+	errStop := errors.New("stop")
+	var calls int
+	err := client.CallBatchStream(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: 1},
+		{Method: "echo", Params: 2},
+	}, func(req BatchRequest, resp BatchResponse) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Client.CallBatchStream() error got %v, want %v", err, errStop)
+	}
+	if calls != 1 {
+		t.Errorf("callback is called %d times, want 1", calls)
+	}
+}
+
+func TestBatchForEach(t *testing.T) {
+	server := testBatchServer(t)
+	defer server.Close()
+
+	client := &Client{}
+
+	args := []int{1, 2, 3}
+	results := make([]*int, len(args))
+	for i := range results {
+		results[i] = new(int)
+	}
+
+	reqs := BatchForEach("echo", args, results)
+	if len(reqs) != len(args) {
+		t.Fatalf("BatchForEach() got %d requests, want %d", len(reqs), len(args))
+	}
+
+	if _, err := client.CallBatch(context.Background(), server.URL, reqs); err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	for i, arg := range args {
+		if *results[i] != arg {
+			t.Errorf("result %d got %d, want %d", i, *results[i], arg)
+		}
+	}
+}
+
+func TestClientCallBatchNotificationDedup(t *testing.T) {
+	tests := map[string]struct {
+		opts []Option
+		want []string
+	}{
+		"dedup": {
+			opts: []Option{WithNotificationDedup()},
+			want: []string{"refresh x", "refresh y", "echo"},
+		},
+		"no dedup": {
+			want: []string{"refresh x", "refresh x", "refresh y", "echo"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sent []string
+			server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+				var key string
+				json.Unmarshal(req.Params, &key)
+				sent = append(sent, strings.TrimSpace(req.Method+" "+key))
+
+				var v interface{}
+				json.Unmarshal(req.Params, &v)
+				return v, nil
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			var result string
+			res, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+				{Method: "refresh", Params: "x", Notification: true},
+				{Method: "refresh", Params: "x", Notification: true},
+				{Method: "refresh", Params: "y", Notification: true},
+				{Method: "echo", Params: "", Result: &result},
+			}, tt.opts...)
+			if err != nil {
+				t.Fatalf("Client.CallBatch() failed: %v", err)
+			}
+			resps := res.Responses
+
+			if len(resps) != 4 {
+				t.Errorf("Client.CallBatch() got %d responses, want 4", len(resps))
+			}
+			if !reflect.DeepEqual(sent, tt.want) {
+				t.Errorf("server got %v, want %v", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCallBatchNotificationsOnly(t *testing.T) {
+	server := testBatchServer(t)
+	defer server.Close()
+
+	client := &Client{}
+
+	res, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: 1, Notification: true},
+		{Method: "echo", Params: 2, Notification: true},
+	})
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	resps := res.Responses
+	if len(resps) != 2 {
+		t.Errorf("Client.CallBatch() got %d responses, want 2", len(resps))
+	}
+}
+
+func TestClientCallBatchWithGETBatch(t *testing.T) {
+	var method atomic.Value
+	var query atomic.Value
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		var v interface{}
+		json.Unmarshal(req.Params, &v)
+		return v, nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method.Store(r.Method)
+		batch := r.URL.Query().Get("batch")
+		query.Store(batch)
+		r.Body = ioutil.NopCloser(strings.NewReader(batch))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var first, second int
+	_, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: 1, Result: &first},
+		{Method: "echo", Params: 2, Result: &second},
+	}, WithGETBatch())
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	if got := method.Load().(string); got != http.MethodGet {
+		t.Errorf("server got method %s, want %s", got, http.MethodGet)
+	}
+
+	var reqs []*testRequest
+	if err := json.Unmarshal([]byte(query.Load().(string)), &reqs); err != nil {
+		t.Fatalf("failed to decode batch query: %v", err)
+	}
+	if len(reqs) != 2 || reqs[0].Method != "echo" || string(reqs[1].Params) != "2" {
+		t.Errorf("batch query got %s, want two echo requests", query.Load())
+	}
+
+	if first != 1 || second != 2 {
+		t.Errorf("results got %d, %d, want 1, 2", first, second)
+	}
+}
+
+func TestClientCallBatchWithGETBatchNotification(t *testing.T) {
+	client := &Client{}
+
+	_, err := client.CallBatch(context.Background(), "http://example.com", []BatchRequest{
+		{Method: "echo", Params: 1},
+		{Method: "refresh", Notification: true},
+	}, WithGETBatch())
+	if err == nil {
+		t.Error("Client.CallBatch() must fail with a notification in a GET batch")
+	}
+}
+
+func TestClientBatchCallRawEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*testRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		// respond in reverse order, except notifications.
+		var resps []*testResponse
+		for i := len(reqs) - 1; i >= 0; i-- {
+			if reqs[i].ID == nil {
+				continue
+			}
+			resps = append(resps, &testResponse{
+				JSONRPC: Version,
+				Result:  reqs[i].Params,
+				ID:      reqs[i].ID,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	entries := []json.RawMessage{
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","params":"first","id":1}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"refresh","params":"notification"}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","params":"second","id":{ "key": "two" }}`),
+	}
+
+	resps, err := client.BatchCallRawEntries(context.Background(), server.URL, entries)
+	if err != nil {
+		t.Fatalf("Client.BatchCallRawEntries() failed: %v", err)
+	}
+	if len(resps) != len(entries) {
+		t.Fatalf("Client.BatchCallRawEntries() got %d responses, want %d", len(resps), len(entries))
+	}
+
+	if got := string(resps[0].Result); got != `"first"` {
+		t.Errorf("result of entry 0 got %s, want %s", got, `"first"`)
+	}
+	if resps[1].Result != nil || resps[1].ID != nil {
+		t.Errorf("response to notification got %+v, want zero", resps[1])
+	}
+	if got := string(resps[2].Result); got != `"second"` {
+		t.Errorf("result of entry 2 got %s, want %s", got, `"second"`)
+	}
+}
+
+func TestClientBatchCallRawEntriesDuplicateID(t *testing.T) {
+	client := &Client{}
+
+	_, err := client.BatchCallRawEntries(context.Background(), "http://example.com", []json.RawMessage{
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":1}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":1}`),
+	})
+	if err == nil {
+		t.Error("Client.BatchCallRawEntries() must fail with duplicate ids")
+	}
+}
+
+func TestClientBatchCallRawEntriesMissing(t *testing.T) {
+	// the server responds only to the last entry.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"jsonrpc":"2.0","id":4,"result":4}]`)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	entries := []json.RawMessage{
+		json.RawMessage(`{"jsonrpc":"2.0","method":"refresh"}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":1}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":2}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":3}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":4}`),
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err := client.BatchCallRawEntries(context.Background(), server.URL, entries)
+		if err == nil || !strings.Contains(err.Error(), "entry 1 (id 1) is missing") {
+			t.Fatalf("Client.BatchCallRawEntries() error got %v, want entry 1 missing", err)
+		}
+	}
+}
+
+func TestClientCallBatchDuplicateID(t *testing.T) {
+	// the server responds to the first request twice.
+	duplicate := func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*testRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		resps := []*testResponse{{JSONRPC: Version, Result: 1, ID: reqs[0].ID}}
+		for _, req := range reqs {
+			resps = append(resps, &testResponse{JSONRPC: Version, Result: 1, ID: req.ID})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resps)
+	}
+	server := httptest.NewServer(http.HandlerFunc(duplicate))
+	defer server.Close()
+
+	client := &Client{}
+	reqs := []BatchRequest{{Method: "echo"}, {Method: "echo"}}
+
+	check := func(name string, err error) {
+		t.Helper()
+		var protoErr *ProtocolError
+		if !errors.As(err, &protoErr) || protoErr.Kind != DuplicateID {
+			t.Errorf("%s error got %v, want ProtocolError of %v", name, err, DuplicateID)
+		}
+	}
+
+	_, err := client.CallBatch(context.Background(), server.URL, reqs)
+	check("Client.CallBatch()", err)
+
+	err = client.CallBatchStream(context.Background(), server.URL, reqs, func(BatchRequest, BatchResponse) error {
+		return nil
+	})
+	check("Client.CallBatchStream()", err)
+
+	_, err = client.BatchCallRawEntries(context.Background(), server.URL, []json.RawMessage{
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":1}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"echo","id":2}`),
+	})
+	check("Client.BatchCallRawEntries()", err)
+}
+
+func benchmarkBatchServer(b *testing.B) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*testRequest
+		json.NewDecoder(r.Body).Decode(&reqs)
+
+		resps := make([]*testResponse, len(reqs))
+		for i, req := range reqs {
+			resps[i] = &testResponse{JSONRPC: Version, Result: 1, ID: req.ID}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resps)
+	}))
+}
+
+const benchmarkBatchSize = 1000
+
+func BenchmarkClientCallBatch(b *testing.B) {
+	server := benchmarkBatchServer(b)
+	defer server.Close()
+
+	client := &Client{}
+
+	reqs := make([]BatchRequest, benchmarkBatchSize)
+	for i := range reqs {
+		reqs[i] = BatchRequest{Method: "echo", Params: i}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.CallBatch(context.Background(), server.URL, reqs); err != nil {
+			b.Fatalf("Client.CallBatch() failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkClientBatchCallRawEntries(b *testing.B) {
+	server := benchmarkBatchServer(b)
+	defer server.Close()
+
+	client := &Client{}
+
+	entries := make([]json.RawMessage, benchmarkBatchSize)
+	for i := range entries {
+		entries[i] = json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","method":"echo","params":%d,"id":%d}`, i, i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.BatchCallRawEntries(context.Background(), server.URL, entries); err != nil {
+			b.Fatalf("Client.BatchCallRawEntries() failed: %v", err)
+		}
+	}
+}
+
+func TestClientCallBatchResultMetadata(t *testing.T) {
+	handler := testBatchHandler(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Header().Set("X-Request-Id", "request-id")
+		handler.ServeHTTP(w, r)
+		w.Header().Set("X-Checksum", "checksum")
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	res, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: 1},
+		{Method: "echo", Params: 2},
+	})
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	if len(res.Responses) != 2 {
+		t.Errorf("BatchResult.Responses got %d responses, want 2", len(res.Responses))
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("BatchResult.StatusCode got %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if got := res.Header.Get("X-Request-Id"); got != "request-id" {
+		t.Errorf("BatchResult.Header got X-Request-Id %q, want %q", got, "request-id")
+	}
+	if got := res.Trailer.Get("X-Checksum"); got != "checksum" {
+		t.Errorf("BatchResult.Trailer got X-Checksum %q, want %q", got, "checksum")
+	}
+}
+
+func TestClientCallBatchPagination(t *testing.T) {
+	type listParams struct {
+		Name   string `json:"name"`
+		Cursor int    `json:"cursor"`
+	}
+	type listResult struct {
+		Item string `json:"item"`
+		Next int    `json:"next,omitempty"`
+	}
+
+	pages := map[string]int{"a": 3, "b": 2, "c": 1}
+	var calls int
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		calls++
+		var params listParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &ResponseError{Code: InvalidParams, Message: err.Error()}
+		}
+		res := listResult{Item: fmt.Sprintf("%s%d", params.Name, params.Cursor)}
+		if params.Cursor+1 < pages[params.Name] {
+			res.Next = params.Cursor + 1
+		}
+		return res, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	reqs := []BatchRequest{
+		{Method: "list", Params: listParams{Name: "a"}},
+		{Method: "list", Params: listParams{Name: "b"}},
+		{Method: "list", Params: listParams{Name: "c"}},
+	}
+	res, err := client.CallBatch(context.Background(), server.URL, reqs, WithBatchPagination(func(resp BatchResponse) (interface{}, bool) {
+		var res listResult
+		if err := json.Unmarshal(resp.Result, &res); err != nil || res.Next == 0 {
+			return nil, false
+		}
+		return listParams{Name: res.Item[:1], Cursor: res.Next}, true
+	}))
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	want := [][]string{{"a0", "a1", "a2"}, {"b0", "b1"}, {"c0"}}
+	for i, resp := range res.Responses {
+		if resp.Error != nil {
+			t.Fatalf("Client.CallBatch() response %d error: %v", i, resp.Error)
+		}
+		var items []string
+		for _, page := range resp.Pages {
+			var res listResult
+			if err := json.Unmarshal(page, &res); err != nil {
+				t.Fatalf("failed to decode page: %v", err)
+			}
+			items = append(items, res.Item)
+		}
+		if !reflect.DeepEqual(items, want[i]) {
+			t.Errorf("Client.CallBatch() response %d pages got %v, want %v", i, items, want[i])
+		}
+	}
+	if calls != 6 {
+		t.Errorf("Client.CallBatch() got %d requests, want %d", calls, 6)
+	}
+}
+
+func TestClientCallBatchWithPersistence(t *testing.T) {
+	var received []string
+	handler := testBatchHandler(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		var reqs []testRequest
+		json.Unmarshal(body, &reqs)
+		for _, req := range reqs {
+			if req.ID != nil {
+				var id string
+				json.Unmarshal(req.ID, &id)
+				received = append(received, id)
+			}
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	type persisted struct {
+		batchID string
+		ids     []string
+		state   BatchState
+	}
+
+	tests := map[string]struct {
+		path      string
+		wantErr   bool
+		wantState BatchState
+	}{
+		"completed": {path: "/", wantState: BatchCompleted},
+		"failed":    {path: "/down", wantErr: true, wantState: BatchFailed},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			received = nil
+			var calls []persisted
+			sink := func(batchID string, ids []string, state BatchState) {
+				if state == BatchSent && len(received) > 0 {
+					t.Errorf("sink is called with %v after the batch is sent", state)
+				}
+				calls = append(calls, persisted{batchID, ids, state})
+			}
+
+			_, err := client.CallBatch(context.Background(), server.URL+tt.path, []BatchRequest{
+				{Method: "echo", Params: 1, Result: new(int)},
+				{Method: "echo", Params: "notified", Notification: true},
+				{Method: "echo", Params: 2, Result: new(int)},
+			}, WithBatchPersistence(sink))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.CallBatch() error got %v, want error %v", err, tt.wantErr)
+			}
+
+			if len(calls) != 2 {
+				t.Fatalf("sink got %d calls, want 2", len(calls))
+			}
+			if calls[0].state != BatchSent || calls[1].state != tt.wantState {
+				t.Errorf("sink got states %v, %v, want %v, %v", calls[0].state, calls[1].state, BatchSent, tt.wantState)
+			}
+			if calls[0].batchID == "" || calls[0].batchID != calls[1].batchID {
+				t.Errorf("sink got batch ids %q, %q, want the same id", calls[0].batchID, calls[1].batchID)
+			}
+			if len(calls[0].ids) != 2 || !reflect.DeepEqual(calls[0].ids, calls[1].ids) {
+				t.Errorf("sink got ids %v, %v, want the ids of the 2 requests", calls[0].ids, calls[1].ids)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(calls[0].ids, received) {
+				t.Errorf("sink got ids %v, want %v sent to the server", calls[0].ids, received)
+			}
+		})
+	}
+}
+
+func TestClientCallBatchUnknownID(t *testing.T) {
+	tests := map[string]struct {
+		injected string
+		kind     ProtocolErrorKind
+	}{
+		"uuid not sent": {
+			injected: `{"jsonrpc":"2.0","id":"0b7e4b8e-6f1a-4c52-9a57-0d1c2e3f4a5b","result":"poisoned"}`,
+			kind:     UnknownID,
+		},
+		"string id": {
+			injected: `{"jsonrpc":"2.0","id":"x","result":"poisoned"}`,
+			kind:     UnknownID,
+		},
+		"number id": {
+			injected: `{"jsonrpc":"2.0","id":7,"result":"poisoned"}`,
+			kind:     UnknownID,
+		},
+		"null id of result": {
+			injected: `{"jsonrpc":"2.0","id":null,"result":"poisoned"}`,
+			kind:     NullID,
+		},
+	}
+
+	handler := testBatchHandler(t)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the proxy injects a response to a request never sent.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+				body := bytes.TrimSuffix(bytes.TrimSpace(rec.Body.Bytes()), []byte("]"))
+				fmt.Fprintf(w, "%s,%s]", body, tt.injected)
+			}))
+			defer server.Close()
+
+			client := &Client{}
+			reqs := func(result *int) []BatchRequest {
+				return []BatchRequest{{Method: "echo", Params: 1, Result: result}}
+			}
+
+			var result int
+			_, err := client.CallBatch(context.Background(), server.URL, reqs(&result))
+			var protoErr *ProtocolError
+			if !errors.As(err, &protoErr) || protoErr.Kind != tt.kind {
+				t.Errorf("Client.CallBatch() error got %v, want ProtocolError of %v", err, tt.kind)
+			}
+
+			err = client.CallBatchStream(context.Background(), server.URL, reqs(&result), func(BatchRequest, BatchResponse) error {
+				return nil
+			})
+			if !errors.As(err, &protoErr) || protoErr.Kind != tt.kind {
+				t.Errorf("Client.CallBatchStream() error got %v, want ProtocolError of %v", err, tt.kind)
+			}
+		})
+	}
+}
+
+func TestClientCallBatchNullIDError(t *testing.T) {
+	handler := testBatchHandler(t)
+	// the server responds an error to a request whose id cannot be determined.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		body := bytes.TrimSuffix(bytes.TrimSpace(rec.Body.Bytes()), []byte("]"))
+		fmt.Fprintf(w, `%s,{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid request"}}]`, body)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result int
+	if _, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: 1, Result: &result},
+	}); err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	if result != 1 {
+		t.Errorf("Client.CallBatch() got %d, want 1", result)
+	}
+}
@@ -0,0 +1,188 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// BindOption represents an option used by Bind.
+type BindOption interface {
+	applyBind(opts *bindOptions)
+}
+
+type bindOptionFunc func(opts *bindOptions)
+
+func (f bindOptionFunc) applyBind(opts *bindOptions) {
+	f(opts)
+}
+
+type bindOptions struct {
+	NameTransform func(goName string) string
+	CallOptions   []Option
+}
+
+// WithNameTransform returns a BindOption that derives the method name from the Go field name by transform,
+// for the fields without the jsonrpc tag, e.g. CamelCase, SnakeCase or DotCase.
+// The field name is used as is by default.
+func WithNameTransform(transform func(goName string) string) BindOption {
+	return bindOptionFunc(func(opts *bindOptions) {
+		opts.NameTransform = transform
+	})
+}
+
+// WithBindCallOptions returns a BindOption that passes opts to every call of the bound functions.
+func WithBindCallOptions(callOpts ...Option) BindOption {
+	return bindOptionFunc(func(opts *bindOptions) {
+		opts.CallOptions = append(opts.CallOptions, callOpts...)
+	})
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Bind sets each exported func field of the struct pointed by target to a function calling a method on the url
+// by the client. The method name is the jsonrpc tag of the field, e.g. `jsonrpc:"user.get"`,
+// or derived from the field name, see WithNameTransform.
+// The fields tagged with `jsonrpc:"-"` and the fields of the other types are left as is.
+//
+// The func field must take a context.Context and optionally the params,
+// and return the result and an error, or only an error, e.g.
+//
+//	GetUser func(ctx context.Context, id int) (*User, error)
+//	Ping    func(ctx context.Context) error
+//
+// The func returning only an error expects the method to return no result, see WithExpectNoResult.
+func Bind(client *Client, url string, target interface{}, opts ...BindOption) error {
+	var bindOpts bindOptions
+	for _, opt := range opts {
+		opt.applyBind(&bindOpts)
+	}
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("target must be a pointer to a struct")
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Type.Kind() != reflect.Func {
+			continue
+		}
+
+		method, ok := field.Tag.Lookup("jsonrpc")
+		if method == "-" {
+			continue
+		}
+		if !ok || method == "" {
+			method = field.Name
+			if bindOpts.NameTransform != nil {
+				method = bindOpts.NameTransform(field.Name)
+			}
+		}
+
+		fn, err := bindFunc(client, url, method, field.Type, bindOpts.CallOptions)
+		if err != nil {
+			return fmt.Errorf("failed to bind %s: %w", field.Name, err)
+		}
+		v.Field(i).Set(fn)
+	}
+
+	return nil
+}
+
+// bindFunc returns a function of the type ft calling the method.
+func bindFunc(client *Client, url string, method string, ft reflect.Type, opts []Option) (reflect.Value, error) {
+	if ft.IsVariadic() || ft.NumIn() < 1 || ft.NumIn() > 2 || ft.In(0) != contextType {
+		return reflect.Value{}, errors.New("func must take a context.Context and optionally the params")
+	}
+	if ft.NumOut() < 1 || ft.NumOut() > 2 || ft.Out(ft.NumOut()-1) != errorType {
+		return reflect.Value{}, errors.New("func must return the result and an error, or only an error")
+	}
+	hasResult := ft.NumOut() == 2
+	if !hasResult {
+		opts = append(opts[:len(opts):len(opts)], WithExpectNoResult())
+	}
+
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		ctx, _ := args[0].Interface().(context.Context)
+
+		var params interface{}
+		if len(args) == 2 {
+			params = args[1].Interface()
+		}
+
+		var result reflect.Value
+		var resultPtr interface{}
+		if hasResult {
+			result = reflect.New(ft.Out(0))
+			resultPtr = result.Interface()
+		}
+
+		err := client.Call(ctx, url, method, params, resultPtr, opts...)
+
+		errValue := reflect.Zero(errorType)
+		if err != nil {
+			errValue = reflect.ValueOf(&err).Elem()
+		}
+		if !hasResult {
+			return []reflect.Value{errValue}
+		}
+		if err != nil {
+			return []reflect.Value{reflect.Zero(ft.Out(0)), errValue}
+		}
+		return []reflect.Value{result.Elem(), errValue}
+	}), nil
+}
+
+// CamelCase transforms a Go name into lower camel case, e.g. "GetUser" into "getUser".
+func CamelCase(goName string) string {
+	words := splitWords(goName)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+// SnakeCase transforms a Go name into snake case, e.g. "GetUser" into "get_user".
+func SnakeCase(goName string) string {
+	return strings.ToLower(strings.Join(splitWords(goName), "_"))
+}
+
+// DotCase transforms a Go name into lower case words separated by dots, e.g. "GetUser" into "get.user".
+func DotCase(goName string) string {
+	return strings.ToLower(strings.Join(splitWords(goName), "."))
+}
+
+// splitWords splits a Go name in mixed caps into words, e.g. "GetHTTPStatus" into "Get", "HTTP" and "Status".
+func splitWords(name string) []string {
+	runes := []rune(name)
+
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsUpper(cur) && (!unicode.IsUpper(prev) || nextLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
@@ -0,0 +1,117 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+type testUserAPI struct {
+	GetUser   func(ctx context.Context, id int) (string, error)
+	Ping      func(ctx context.Context) error
+	Rename    func(ctx context.Context, name string) (string, error) `jsonrpc:"user.rename"`
+	Skipped   func(ctx context.Context) error                        `jsonrpc:"-"`
+	unexposed func(ctx context.Context) error
+}
+
+func TestBind(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		methods = append(methods, req.Method)
+		switch req.Method {
+		case "ping", "Ping":
+			return nil, nil
+		case "user.rename":
+			return "renamed", nil
+		}
+		return req.Method, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		opts []BindOption
+		want string
+	}{
+		"default":   {want: "GetUser"},
+		"camelCase": {opts: []BindOption{WithNameTransform(CamelCase)}, want: "getUser"},
+		"snakeCase": {opts: []BindOption{WithNameTransform(SnakeCase)}, want: "get_user"},
+		"dotCase":   {opts: []BindOption{WithNameTransform(DotCase)}, want: "get.user"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var api testUserAPI
+			if err := Bind(client, server.URL, &api, tt.opts...); err != nil {
+				t.Fatalf("Bind() failed: %v", err)
+			}
+
+			got, err := api.GetUser(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("GetUser() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetUser() called %q, want %q", got, tt.want)
+			}
+
+			if err := api.Ping(context.Background()); err != nil {
+				t.Errorf("Ping() failed: %v", err)
+			}
+			if got, err := api.Rename(context.Background(), "gopher"); err != nil || got != "renamed" {
+				t.Errorf("Rename() got %q, %v, want %q", got, err, "renamed")
+			}
+			if api.Skipped != nil || api.unexposed != nil {
+				t.Error("Bind() must not set the skipped fields")
+			}
+		})
+	}
+}
+
+func TestBindError(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+	}))
+	defer server.Close()
+
+	var api testUserAPI
+	if err := Bind(&Client{}, server.URL, &api); err != nil {
+		t.Fatalf("Bind() failed: %v", err)
+	}
+
+	_, err := api.GetUser(context.Background(), 1)
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != InternalError {
+		t.Errorf("GetUser() error got %v, want InternalError", err)
+	}
+
+	var invalid struct {
+		Get func(id int) (string, error)
+	}
+	if err := Bind(&Client{}, server.URL, &invalid); err == nil {
+		t.Error("Bind() must fail for a func without context.Context")
+	}
+}
+
+func TestNameTransform(t *testing.T) {
+	tests := map[string]struct {
+		camel, snake, dot string
+	}{
+		"GetUser":       {"getUser", "get_user", "get.user"},
+		"GetHTTPStatus": {"getHttpStatus", "get_http_status", "get.http.status"},
+		"ID":            {"id", "id", "id"},
+	}
+
+	for name, tt := range tests {
+		if got := CamelCase(name); got != tt.camel {
+			t.Errorf("CamelCase(%q) got %q, want %q", name, got, tt.camel)
+		}
+		if got := SnakeCase(name); got != tt.snake {
+			t.Errorf("SnakeCase(%q) got %q, want %q", name, got, tt.snake)
+		}
+		if got := DotCase(name); got != tt.dot {
+			t.Errorf("DotCase(%q) got %q, want %q", name, got, tt.dot)
+		}
+	}
+}
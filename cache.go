@@ -0,0 +1,180 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultResultCacheSize is the maximum number of the results kept by the ResultCache of NewResultCache.
+const DefaultResultCacheSize = 1024
+
+// ResultCache caches the results of the calls by the endpoint, the method and the params for a fixed duration.
+// It is safe for concurrent use, and can be shared by the calls given WithResultCache.
+type ResultCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]resultCacheEntry
+}
+
+type resultCacheEntry struct {
+	result  json.RawMessage
+	expires time.Time
+}
+
+// NewResultCache returns a new ResultCache keeping the results for ttl,
+// up to DefaultResultCacheSize results.
+func NewResultCache(ttl time.Duration) *ResultCache {
+	return NewResultCacheSize(ttl, DefaultResultCacheSize)
+}
+
+// NewResultCacheSize returns a new ResultCache keeping the results for ttl, up to maxEntries results.
+// If the cache is full, the expired results are evicted, and then the oldest result if none has expired,
+// so a stale result for WithServeStaleOnError may be evicted before maxStale elapses.
+func NewResultCacheSize(ttl time.Duration, maxEntries int) *ResultCache {
+	return &ResultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]resultCacheEntry),
+	}
+}
+
+// WithResultCache returns an Option that serves the result from cache without a network call
+// if the same method of the same endpoint is called with the same params within its ttl,
+// and caches the result of the call otherwise.
+// Errors and empty results, e.g. of 202 Accepted, are not cached,
+// and the params streamed by a ParamsEncoder are never cached.
+//
+// CallStats.FromCache reports whether the result is served from cache.
+func WithResultCache(cache *ResultCache) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ResultCache = cache
+	})
+}
+
+// WithServeStaleOnError returns an Option that serves the last result cached by WithResultCache
+// if the call fails with a transport error or a 5xx response,
+// and the result has expired for no longer than maxStale.
+// It is for read methods where a stale result is better than an error.
+//
+// CallStats.Stale reports whether the stale result is served.
+func WithServeStaleOnError(maxStale time.Duration) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.MaxStale = maxStale
+	})
+}
+
+// The expired entries are kept to serve them as stale results,
+// until they are replaced by the next results or evicted from the full cache.
+func (cache *ResultCache) get(key string, now time.Time) (json.RawMessage, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expires) {
+		return nil, false
+	}
+
+	return entry.result, true
+}
+
+// stale returns the result of key if it has expired for no longer than maxStale.
+func (cache *ResultCache) stale(key string, now time.Time, maxStale time.Duration) (json.RawMessage, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[key]
+	if !ok || now.Sub(entry.expires) > maxStale {
+		return nil, false
+	}
+
+	return entry.result, true
+}
+
+// serveStale decodes the stale result of key into result if err allows it by WithServeStaleOnError,
+// and reports whether it is served.
+func (client *Client) serveStale(ctx context.Context, key string, result interface{}, err error, opts callOptions) (bool, error) {
+	if opts.ResultCache == nil || opts.MaxStale <= 0 || ctx.Err() != nil || !degraded(err) {
+		return false, nil
+	}
+
+	raw, ok := opts.ResultCache.stale(key, client.clockOrDefault().Now(), opts.MaxStale)
+	if !ok {
+		return false, nil
+	}
+
+	if opts.stats != nil {
+		opts.stats.FromCache = true
+		opts.stats.Stale = true
+	}
+	if opts.ExpectNoResult {
+		return true, nil
+	}
+	return true, decodeResult(raw, result, opts)
+}
+
+// degraded reports whether err is a transport error or a 5xx response.
+func degraded(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+
+	return isTransportError(err)
+}
+
+func (cache *ResultCache) put(key string, result json.RawMessage, now time.Time) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if _, ok := cache.entries[key]; !ok && cache.maxEntries > 0 && len(cache.entries) >= cache.maxEntries {
+		cache.evict(now)
+	}
+
+	cache.entries[key] = resultCacheEntry{
+		result:  append(json.RawMessage(nil), result...),
+		expires: now.Add(cache.ttl),
+	}
+}
+
+// evict evicts the expired entries, or the oldest entry if none has expired. cache.mu must be held.
+func (cache *ResultCache) evict(now time.Time) {
+	var oldest string
+	var oldestExpires time.Time
+	for key, entry := range cache.entries {
+		if !now.Before(entry.expires) {
+			delete(cache.entries, key)
+			continue
+		}
+		if oldest == "" || entry.expires.Before(oldestExpires) {
+			oldest, oldestExpires = key, entry.expires
+		}
+	}
+
+	if len(cache.entries) >= cache.maxEntries {
+		delete(cache.entries, oldest)
+	}
+}
+
+// resultCacheKey returns the key of the result cache for the call of the method with the params to the endpoint.
+func resultCacheKey(endpoint string, method string, params interface{}, opts callOptions) (string, error) {
+	params, err := opts.marshalParams(method, params)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	return endpoint + "\x00" + method + "\x00" + string(b), nil
+}
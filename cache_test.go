@@ -0,0 +1,206 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCallWithResultCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return atomic.AddInt32(&calls, 1), nil
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(WithClock(clock))
+	cache := WithResultCache(NewResultCache(time.Minute))
+
+	tests := []struct {
+		name      string
+		params    interface{}
+		advance   time.Duration
+		want      int
+		fromCache bool
+	}{
+		{name: "miss", params: []int{1}, want: 1},
+		{name: "hit", params: []int{1}, want: 1, fromCache: true},
+		{name: "other params", params: []int{2}, want: 2},
+		{name: "expired", params: []int{1}, advance: time.Minute, want: 3},
+	}
+
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+
+		var result int
+		stats, err := client.CallWithStats(context.Background(), server.URL, "count", tt.params, &result, cache)
+		if err != nil {
+			t.Fatalf("%s: Client.CallWithStats() failed: %v", tt.name, err)
+		}
+		if result != tt.want {
+			t.Errorf("%s: Client.CallWithStats() got %d, want %d", tt.name, result, tt.want)
+		}
+		if stats.FromCache != tt.fromCache {
+			t.Errorf("%s: CallStats.FromCache got %v, want %v", tt.name, stats.FromCache, tt.fromCache)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&calls); calls != 3 {
+		t.Errorf("server got %d calls, want 3", calls)
+	}
+}
+
+func TestClientCallWithServeStaleOnError(t *testing.T) {
+	var failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "fresh", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(WithClock(clock))
+	opts := Options(WithResultCache(NewResultCache(time.Minute)), WithServeStaleOnError(time.Minute))
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "read", nil, &result, opts); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	atomic.StoreInt32(&failing, 1)
+	clock.Advance(90 * time.Second)
+
+	result = ""
+	stats, err := client.CallWithStats(context.Background(), server.URL, "read", nil, &result, opts)
+	if err != nil {
+		t.Fatalf("Client.CallWithStats() failed: %v", err)
+	}
+	if result != "fresh" {
+		t.Errorf("Client.CallWithStats() got %q, want %q", result, "fresh")
+	}
+	if !stats.Stale || !stats.FromCache {
+		t.Errorf("CallStats got Stale %v and FromCache %v, want true", stats.Stale, stats.FromCache)
+	}
+
+	clock.Advance(time.Minute)
+
+	err = client.Call(context.Background(), server.URL, "read", nil, &result, opts)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Client.Call() beyond maxStale error got %v, want 503 StatusError", err)
+	}
+}
+
+func TestClientCallWithResultCacheEndpoints(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return name, nil
+		}))
+	}
+	server1 := newServer("server1")
+	defer server1.Close()
+	server2 := newServer("server2")
+	defer server2.Close()
+
+	client := NewClient()
+	cache := WithResultCache(NewResultCache(time.Minute))
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: server1.URL, want: "server1"},
+		{url: server2.URL, want: "server2"},
+		{url: server1.URL, want: "server1"},
+	}
+
+	for _, tt := range tests {
+		var result string
+		if err := client.Call(context.Background(), tt.url, "whoami", []int{1}, &result, cache); err != nil {
+			t.Fatalf("Client.Call() failed: %v", err)
+		}
+		if result != tt.want {
+			t.Errorf("Client.Call() to %s got %q, want %q", tt.url, result, tt.want)
+		}
+	}
+}
+
+func TestClientCallWithResultCacheSize(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return atomic.AddInt32(&calls, 1), nil
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(WithClock(clock))
+	resultCache := NewResultCacheSize(time.Minute, 2)
+	cache := WithResultCache(resultCache)
+
+	tests := []struct {
+		name      string
+		params    interface{}
+		advance   time.Duration
+		fromCache bool
+	}{
+		{name: "miss 1", params: []int{1}},
+		{name: "miss 2", params: []int{2}, advance: time.Second},
+		{name: "evict 1", params: []int{3}, advance: time.Second},
+		{name: "hit 2", params: []int{2}, fromCache: true},
+		{name: "evicted 1", params: []int{1}},
+		{name: "expired all", params: []int{4}, advance: time.Minute},
+	}
+
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+
+		var result int
+		stats, err := client.CallWithStats(context.Background(), server.URL, "count", tt.params, &result, cache)
+		if err != nil {
+			t.Fatalf("%s: Client.CallWithStats() failed: %v", tt.name, err)
+		}
+		if stats.FromCache != tt.fromCache {
+			t.Errorf("%s: CallStats.FromCache got %v, want %v", tt.name, stats.FromCache, tt.fromCache)
+		}
+		if n := len(resultCache.entries); n > 2 {
+			t.Errorf("%s: ResultCache got %d entries, want at most 2", tt.name, n)
+		}
+	}
+
+	if n := len(resultCache.entries); n != 1 {
+		t.Errorf("ResultCache got %d entries after all expired, want 1", n)
+	}
+}
+
+func TestClientCallWithResultCacheEmptyResult(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+	opts := Options(WithResultCache(NewResultCache(time.Minute)), WithAcceptStatus(http.StatusAccepted))
+
+	for i := 0; i < 2; i++ {
+		var result string
+		if err := client.Call(context.Background(), server.URL, "accept", nil, &result, opts); err != nil {
+			t.Fatalf("Client.Call() %d failed: %v", i, err)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls, want 2 without caching the empty result", calls)
+	}
+}
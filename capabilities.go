@@ -0,0 +1,66 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CapabilitiesMethod is the method name Negotiate calls to query the capabilities of the server.
+const CapabilitiesMethod = "rpc.capabilities"
+
+// ErrBatchNotSupported is returned when a batch is sent to the url
+// whose server is negotiated by Negotiate not to support batches.
+var ErrBatchNotSupported = errors.New("server does not support batch requests")
+
+// Capabilities is the features supported by the server, responded to CapabilitiesMethod.
+type Capabilities struct {
+	// Batch reports whether the server accepts batch requests.
+	Batch bool `json:"batch"`
+	// Compression reports whether the server compresses the responses.
+	Compression bool `json:"compression"`
+	// Subscriptions reports whether the server supports subscriptions.
+	Subscriptions bool `json:"subscriptions"`
+}
+
+// Negotiate queries the capabilities of the server on the url by calling CapabilitiesMethod,
+// and returns them.
+// The client remembers the capabilities for the url, and the subsequent batches sent to the url fail
+// with ErrBatchNotSupported without sending them if the server does not support batches.
+func (client *Client) Negotiate(ctx context.Context, url string, opts ...Option) (Capabilities, error) {
+	var caps Capabilities
+	if err := client.Call(ctx, url, CapabilitiesMethod, nil, &caps, opts...); err != nil {
+		return Capabilities{}, fmt.Errorf("failed to negotiate capabilities: %w", err)
+	}
+
+	client.capabilitiesMu.Lock()
+	defer client.capabilitiesMu.Unlock()
+
+	if client.capabilities == nil {
+		client.capabilities = make(map[string]Capabilities)
+	}
+	client.capabilities[client.endpoint(url)] = caps
+
+	return caps, nil
+}
+
+// checkBatchSupported returns ErrBatchNotSupported if the server on the url is negotiated not to support batches.
+func (client *Client) checkBatchSupported(url string) error {
+	client.capabilitiesMu.RLock()
+	defer client.capabilitiesMu.RUnlock()
+
+	if caps, ok := client.capabilities[client.endpoint(url)]; ok && !caps.Batch {
+		return ErrBatchNotSupported
+	}
+
+	return nil
+}
+
+// endpoint returns the url, or the Endpoint of the client if the url is empty.
+func (client *Client) endpoint(url string) string {
+	if url == "" {
+		return client.Endpoint
+	}
+
+	return url
+}
@@ -0,0 +1,77 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientNegotiate(t *testing.T) {
+	tests := map[string]struct {
+		batch bool
+	}{
+		"supported":   {batch: true},
+		"unsupported": {batch: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var batches int32
+			handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+				if req.Method == CapabilitiesMethod {
+					return &Capabilities{Batch: tt.batch, Compression: true}, nil
+				}
+				return "ok", nil
+			})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/batch" {
+					atomic.AddInt32(&batches, 1)
+				}
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client := &Client{}
+			url := server.URL + "/batch"
+
+			var result1, result2 string
+			reqs := []BatchRequest{
+				{Method: "first", Result: &result1},
+				{Method: "second", Result: &result2},
+			}
+			if _, err := client.CallBatch(context.Background(), url, reqs); err != nil {
+				t.Fatalf("Client.CallBatch() before Negotiate failed: %v", err)
+			}
+
+			caps, err := client.Negotiate(context.Background(), url)
+			if err != nil {
+				t.Fatalf("Client.Negotiate() failed: %v", err)
+			}
+			if caps.Batch != tt.batch || !caps.Compression {
+				t.Errorf("Client.Negotiate() got %+v, want batch %v and compression", caps, tt.batch)
+			}
+			atomic.StoreInt32(&batches, 0)
+
+			_, err = client.CallBatch(context.Background(), url, reqs)
+			if tt.batch {
+				if err != nil {
+					t.Fatalf("Client.CallBatch() failed: %v", err)
+				}
+				if n := atomic.LoadInt32(&batches); n != 1 {
+					t.Errorf("server got %d batches, want 1", n)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrBatchNotSupported) {
+				t.Errorf("Client.CallBatch() error got %v, want ErrBatchNotSupported", err)
+			}
+			if n := atomic.LoadInt32(&batches); n != 0 {
+				t.Errorf("server got %d batches, want 0", n)
+			}
+		})
+	}
+}
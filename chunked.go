@@ -0,0 +1,34 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// CallChunked calls the method on the url with the items split into chunks of at most chunkSize,
+// each chunk is sent as positional params of a separate call.
+// After all calls succeed, merge is called with the results of the calls in order of the chunks.
+func (client *Client) CallChunked(ctx context.Context, url string, method string, items []interface{}, chunkSize int, merge func(results []json.RawMessage) error, opts ...Option) error {
+	if chunkSize <= 0 {
+		return errors.New("chunk size must be positive")
+	}
+
+	results := make([]json.RawMessage, 0, (len(items)+chunkSize-1)/chunkSize)
+	for start := 0; start < len(items); start += chunkSize {
+		end := start + chunkSize
+		if end > len(items) {
+			end = len(items)
+		}
+
+		var result json.RawMessage
+		if err := client.Call(ctx, url, method, items[start:end], &result, opts...); err != nil {
+			return fmt.Errorf("failed to call chunk [%d:%d]: %w", start, end, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return merge(results)
+}
@@ -0,0 +1,69 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientCallChunked(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		calls++
+
+		var ids []int
+		if err := json.Unmarshal(req.Params, &ids); err != nil {
+			return nil, &ResponseError{Code: InvalidParams, Message: err.Error()}
+		}
+		if len(ids) > 2 {
+			return nil, &ResponseError{Code: InvalidParams, Message: "too many ids"}
+		}
+
+		names := make([]string, len(ids))
+		for i, id := range ids {
+			names[i] = string(rune('a' + id))
+		}
+		return names, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	items := []interface{}{0, 1, 2, 3, 4}
+
+	var names []string
+	merge := func(results []json.RawMessage) error {
+		for _, result := range results {
+			var chunk []string
+			if err := json.Unmarshal(result, &chunk); err != nil {
+				return err
+			}
+			names = append(names, chunk...)
+		}
+		return nil
+	}
+
+	if err := client.CallChunked(context.Background(), server.URL, "names", items, 2, merge); err != nil {
+		t.Fatalf("Client.CallChunked() failed: %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("server got %d calls, want 3", calls)
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Client.CallChunked() merged results got %v, want %v", names, want)
+	}
+}
+
+func TestClientCallChunkedInvalidChunkSize(t *testing.T) {
+	client := &Client{}
+
+	err := client.CallChunked(context.Background(), "http://example.com", "names", []interface{}{1}, 0, func([]json.RawMessage) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("Client.CallChunked() must fail with chunk size 0")
+	}
+}
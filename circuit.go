@@ -0,0 +1,179 @@
+package jsonrpc
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CircuitState is a state of the circuit breaker of a host.
+type CircuitState int
+
+const (
+	// CircuitClosed means that the calls to the host are sent as usual.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means that the calls to the host fail with ErrCircuitOpen without sending them.
+	CircuitOpen
+	// CircuitHalfOpen means that a trial call is sent to the host to decide whether to close the circuit.
+	CircuitHalfOpen
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "Closed"
+	case CircuitOpen:
+		return "Open"
+	case CircuitHalfOpen:
+		return "HalfOpen"
+	}
+
+	return "Unknown"
+}
+
+// ErrCircuitOpen is returned when the call is rejected by the open circuit breaker of the host.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// WithCircuitBreaker returns a ClientOption that enables the circuit breakers per host.
+// The circuit of a host opens after threshold consecutive calls fail because the host is unavailable,
+// i.e. the request cannot be sent or the server responds a 5xx status,
+// and the calls to the host fail with ErrCircuitOpen without sending them.
+// After the cooldown, measured by the clock of the Client, the circuit half-opens and a trial call is sent.
+// The circuit closes if the trial call succeeds, otherwise it opens again.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.CircuitThreshold = threshold
+		opts.CircuitCooldown = cooldown
+	})
+}
+
+// WithCircuitStateCallback returns a ClientOption that calls fn whenever the circuit breaker of a host
+// given by WithCircuitBreaker changes its state.
+// fn is called synchronously by the call causing the change, so it must not block.
+func WithCircuitStateCallback(fn func(host string, from, to CircuitState)) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.CircuitStateCallback = fn
+	})
+}
+
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(host string, from, to CircuitState)
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+// circuit is the state of the circuit breaker of a host.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// withCircuit calls fn if the circuit of the host of rawURL allows it, and records the result to the circuit.
+func (client *Client) withCircuit(rawURL string, fn func() error) error {
+	breaker := client.breaker
+	if breaker == nil {
+		return fn()
+	}
+
+	u, err := url.Parse(client.endpoint(rawURL))
+	if err != nil || u.Host == "" {
+		return fn()
+	}
+	host := u.Host
+
+	if err := client.circuitAllow(host); err != nil {
+		return err
+	}
+
+	err = fn()
+	client.circuitRecord(host, err)
+
+	return err
+}
+
+func (client *Client) circuitAllow(host string) error {
+	breaker := client.breaker
+	now := client.clockOrDefault().Now()
+
+	breaker.mu.Lock()
+	c := breaker.circuit(host)
+	from := c.state
+	switch c.state {
+	case CircuitOpen:
+		if now.Sub(c.openedAt) < breaker.cooldown {
+			breaker.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		c.state = CircuitHalfOpen
+		c.trial = true
+	case CircuitHalfOpen:
+		if c.trial {
+			breaker.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		c.trial = true
+	}
+	to := c.state
+	breaker.mu.Unlock()
+
+	breaker.changed(host, from, to)
+
+	return nil
+}
+
+func (client *Client) circuitRecord(host string, err error) {
+	breaker := client.breaker
+	now := client.clockOrDefault().Now()
+
+	breaker.mu.Lock()
+	c := breaker.circuit(host)
+	from := c.state
+	c.trial = false
+	switch {
+	case err != nil && isContextError(err):
+		// the call is abandoned by the caller, which tells nothing about the host.
+	case err != nil && endpointFailed(err):
+		c.failures++
+		if c.state == CircuitHalfOpen || c.failures >= breaker.threshold {
+			c.state = CircuitOpen
+			c.openedAt = now
+		}
+	default:
+		c.failures = 0
+		c.state = CircuitClosed
+	}
+	to := c.state
+	breaker.mu.Unlock()
+
+	breaker.changed(host, from, to)
+}
+
+func (breaker *circuitBreaker) circuit(host string) *circuit {
+	if breaker.hosts == nil {
+		breaker.hosts = make(map[string]*circuit)
+	}
+	c, ok := breaker.hosts[host]
+	if !ok {
+		c = &circuit{}
+		breaker.hosts[host] = c
+	}
+
+	return c
+}
+
+func (breaker *circuitBreaker) changed(host string, from, to CircuitState) {
+	if from != to && breaker.onChange != nil {
+		breaker.onChange(host, from, to)
+	}
+}
+
+func isContextError(err error) bool {
+	var ctxErr *ContextError
+	return errors.As(err, &ctxErr)
+}
@@ -0,0 +1,95 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCallCircuitBreaker(t *testing.T) {
+	var failing int32 = 1
+	var calls int32
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	type transition struct {
+		from, to CircuitState
+	}
+	var transitions []transition
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	clock := newFakeClock()
+	client := NewClient(
+		WithClock(clock),
+		WithCircuitBreaker(2, time.Minute),
+		WithCircuitStateCallback(func(h string, from, to CircuitState) {
+			if h != host {
+				t.Errorf("callback got host %s, want %s", h, host)
+			}
+			transitions = append(transitions, transition{from, to})
+		}),
+	)
+
+	call := func() error {
+		var result string
+		return client.Call(context.Background(), server.URL, "test", nil, &result)
+	}
+
+	for i := 0; i < 2; i++ {
+		var statusErr *StatusError
+		if err := call(); !errors.As(err, &statusErr) {
+			t.Fatalf("Client.Call() error got %v, want *StatusError", err)
+		}
+	}
+	if err := call(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Client.Call() error got %v, want ErrCircuitOpen", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls, want 2", calls)
+	}
+
+	// the trial call fails, and the circuit opens again.
+	clock.Advance(time.Minute)
+	if err := call(); errors.Is(err, ErrCircuitOpen) || err == nil {
+		t.Fatalf("Client.Call() error got %v, want the error of the trial call", err)
+	}
+	if err := call(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Client.Call() error got %v, want ErrCircuitOpen", err)
+	}
+
+	// the trial call succeeds, and the circuit closes.
+	atomic.StoreInt32(&failing, 0)
+	clock.Advance(time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := call(); err != nil {
+			t.Fatalf("Client.Call() failed: %v", err)
+		}
+	}
+
+	want := []transition{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("transitions got %v, want %v", transitions, want)
+	}
+}
@@ -3,55 +3,243 @@ package jsonrpc
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
-
-	"github.com/google/uuid"
+	"net/http/httptrace"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Version is a JSON-RPC version.
 const Version = "2.0"
 
+const defaultCharset = "utf-8"
+
 // Client represents a JSPN-RPC 2.0 Client.
 type Client struct {
+	// metrics is the first field to keep its int64 counters 64-bit aligned for the atomic operations on 386 and ARM.
+	metrics clientMetrics
+
 	// HTTPClient is a HTTP client you want to use.
 	// Use http.DefaultClient if it is nil.
+	//
+	// A transport can be shared by multiple Clients safely,
+	// pass HTTP clients with the same transport to share its connection pool among them.
+	// Calls with WithForceHTTP1 or WithForceHTTP2 use transports cloned per Client.
 	HTTPClient *http.Client
+
+	// Endpoint is the url used by calls with an empty url.
+	Endpoint string
+
+	// Header is the header sent with every request of the Client.
+	// The header given by WithHeader is added to it.
+	Header http.Header
+
+	// CorrelationHeader is the header name to send the correlation id given by WithCorrelationID.
+	// Use DefaultCorrelationHeader if it is empty.
+	CorrelationHeader string
+
+	// AcceptStatus is the HTTP status codes of the responses accepted as success in addition to 200 OK.
+	// WithAcceptStatus overrides it for a call.
+	AcceptStatus []int
+
+	forcedOnce  sync.Once
+	http1Client *http.Client
+	http2Client *http.Client
+	forcedErr   error
+
+	transferMu sync.Mutex
+	sent       int64
+	recv       int64
+
+	resultTypesMu sync.RWMutex
+	resultTypes   map[string]reflect.Type
+
+	errorDataTypesMu sync.RWMutex
+	errorDataTypes   map[ErrorCode]reflect.Type
+
+	capabilitiesMu sync.RWMutex
+	capabilities   map[string]Capabilities
+
+	clock Clock
+
+	hostHeaders map[string]http.Header
+	breaker     *circuitBreaker
+	retrySem    chan struct{}
+
+	learnedTimeouts *learnedTimeouts
+	streams         streamTracker
+	offline         *offlineQueue
+
+	lifecycle lifecycle
+
+	hostStatsMu sync.Mutex
+	hostStats   map[string]*hostCounter
+}
+
+// NewClient returns a new Client configured by opts.
+// A new transport is created for the Client if any option tuning the transport is set,
+// otherwise the Client uses http.DefaultClient.
+func NewClient(opts ...ClientOption) *Client {
+	var clientOpts clientOptions
+	for _, opt := range opts {
+		opt.applyClient(&clientOpts)
+	}
+
+	client := &Client{}
+	if clientOpts.tunesTransport() {
+		client.HTTPClient = &http.Client{
+			Transport: clientOpts.transport(),
+		}
+	}
+	for method, proto := range clientOpts.ResultTypes {
+		client.RegisterResultType(method, proto)
+	}
+	for code, proto := range clientOpts.ErrorDataTypes {
+		client.RegisterErrorDataType(code, proto)
+	}
+	client.clock = clientOpts.Clock
+	client.streams.max = clientOpts.MaxSubscriptions
+	client.hostHeaders = clientOpts.HostHeaders
+	if clientOpts.LearnedTimeoutHeader != "" {
+		client.learnedTimeouts = &learnedTimeouts{
+			header:     clientOpts.LearnedTimeoutHeader,
+			maxTimeout: clientOpts.LearnedTimeoutCap,
+			hosts:      make(map[string]time.Duration),
+		}
+	}
+	if clientOpts.OfflineQueue != nil {
+		client.offline = newOfflineQueue(clientOpts.OfflineQueue)
+	}
+	if clientOpts.RetryConcurrency > 0 {
+		client.retrySem = make(chan struct{}, clientOpts.RetryConcurrency)
+	}
+	if clientOpts.CircuitThreshold > 0 {
+		client.breaker = &circuitBreaker{
+			threshold: clientOpts.CircuitThreshold,
+			cooldown:  clientOpts.CircuitCooldown,
+			onChange:  clientOpts.CircuitStateCallback,
+		}
+	}
+
+	return client
 }
 
 type request struct {
 	JSONRPC string      `json:"jsonrpc"`
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params,omitempty"`
-	ID      uuid.UUID   `json:"id"`
+	ID      requestID   `json:"id"`
 }
 
-func requestBody(method string, params interface{}) (uuid.UUID, io.Reader, error) {
+func requestBody(method string, params interface{}, opts callOptions) (requestID, []byte, error) {
+	params, err := opts.marshalParams(method, params)
+	if err != nil {
+		return requestID{}, nil, err
+	}
+
+	id, err := opts.newID()
+	if err != nil {
+		return requestID{}, nil, err
+	}
+
 	r := &request{
 		JSONRPC: Version,
 		Method:  method,
 		Params:  params,
-		ID:      uuid.New(),
+		ID:      id,
+	}
+
+	if opts.EnvelopeMarshaler != nil {
+		id, err := json.Marshal(r.ID)
+		if err != nil {
+			return requestID{}, nil, fmt.Errorf("failed to marshal request ID: %w", err)
+		}
+
+		b, err := opts.EnvelopeMarshaler(method, params, id)
+		if err != nil {
+			return requestID{}, nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		return r.ID, b, nil
 	}
 
-	b, err := json.Marshal(r)
+	b, err := json.Marshal(opts.EnvelopeKeyCase.envelope(r))
 	if err != nil {
-		return uuid.Nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+		return requestID{}, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return r.ID, b, nil
+}
+
+// KeyCase is a casing style of the keys of the request envelope.
+type KeyCase int
+
+const (
+	// LowerCase is the standard lowercase style, e.g. "jsonrpc", "method".
+	LowerCase KeyCase = iota
+	// PascalCase is the PascalCase style, e.g. "JSONRPC", "Method".
+	PascalCase
+)
+
+type pascalRequest struct {
+	JSONRPC string      `json:"JSONRPC"`
+	Method  string      `json:"Method"`
+	Params  interface{} `json:"Params,omitempty"`
+	ID      requestID   `json:"ID"`
+}
+
+// envelope returns a value that marshals r with the keys in the style.
+func (style KeyCase) envelope(r *request) interface{} {
+	if style == PascalCase {
+		return (*pascalRequest)(r)
+	}
+
+	return r
+}
+
+// notification is a request without id, to which the server does not respond.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type pascalNotification struct {
+	JSONRPC string      `json:"JSONRPC"`
+	Method  string      `json:"Method"`
+	Params  interface{} `json:"Params,omitempty"`
+}
+
+// notificationEnvelope returns a value that marshals n with the keys in the style.
+func (style KeyCase) notificationEnvelope(n *notification) interface{} {
+	if style == PascalCase {
+		return (*pascalNotification)(n)
 	}
 
-	return r.ID, bytes.NewReader(b), nil
+	return n
 }
 
+// response is a response from the server.
+// The keys are matched case-insensitively, so the response in any casing style is accepted.
+// The id is left raw, so that an id of any type can be matched against the ids sent.
 type response struct {
 	JSONRPC string          `json:"jsonrpc"`
 	Result  json.RawMessage `json:"result"`
 	Error   *ResponseError  `json:"error"`
-	ID      uuid.UUID       `json:"id"`
+	ID      json.RawMessage `json:"id"`
 }
 
 // ErrorCode is a number that indicates the error type that occurred.
@@ -71,117 +259,1413 @@ const (
 	InternalError ErrorCode = -32603
 )
 
+// stringErrorCodes maps the string codes responded by non-compliant servers to the error codes.
+var stringErrorCodes = map[string]ErrorCode{
+	"PARSE_ERROR":      ParseError,
+	"INVALID_REQUEST":  InvalidRequest,
+	"METHOD_NOT_FOUND": MethodNotFound,
+	"INVALID_PARAMS":   InvalidParams,
+	"INTERNAL_ERROR":   InternalError,
+}
+
 // ResponseError represents an error responded by the server.
 type ResponseError struct {
 	Code    ErrorCode   `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+
+	// StringCode is the raw code if the server responds the code as a string, e.g. "NOT_FOUND".
+	// Code is set to the corresponding error code if the string is a known one, otherwise 0.
+	StringCode string `json:"-"`
+
+	// OriginalCode is the code responded by the server before it is mapped by WithErrorCodeMapper.
+	// It is set only if WithErrorCodeMapper is given.
+	OriginalCode ErrorCode `json:"-"`
 }
 
 func (err *ResponseError) Error() string {
+	if err.StringCode != "" {
+		return fmt.Sprintf("%s (%s)", err.Message, err.StringCode)
+	}
+
 	return fmt.Sprintf("%s (%d)", err.Message, err.Code)
 }
 
+// MarshalJSON implements json.Marshaler.
+// It marshals err to a JSON-RPC error object, with the stringCode and the originalCode members
+// if StringCode and OriginalCode are set, so that the error can be logged in a structured form.
+func (err *ResponseError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Code         ErrorCode   `json:"code"`
+		Message      string      `json:"message"`
+		Data         interface{} `json:"data,omitempty"`
+		StringCode   string      `json:"stringCode,omitempty"`
+		OriginalCode ErrorCode   `json:"originalCode,omitempty"`
+	}{
+		Code:         err.Code,
+		Message:      err.Message,
+		Data:         err.Data,
+		StringCode:   err.StringCode,
+		OriginalCode: err.OriginalCode,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It accepts the code as a string as well as a number.
+func (err *ResponseError) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+		Data    interface{}     `json:"data"`
+	}
+	if e := json.Unmarshal(b, &v); e != nil {
+		return e
+	}
+
+	*err = ResponseError{
+		Message: v.Message,
+		Data:    v.Data,
+	}
+
+	code := bytes.TrimSpace(v.Code)
+	switch {
+	case len(code) > 0 && code[0] == '"':
+		if e := json.Unmarshal(code, &err.StringCode); e != nil {
+			return e
+		}
+		err.Code = stringErrorCodes[err.StringCode]
+	case !isNullJSON(code):
+		if e := json.Unmarshal(code, &err.Code); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
 // Call calls the method on the url with the params,
 // and stores result responded by the server in the result.
 func (client *Client) Call(ctx context.Context, url string, method string, params interface{}, result interface{}, opts ...Option) error {
+	ctx, done := client.begin(ctx)
+	defer done()
+
 	if method == "" {
 		return errors.New("method is empty")
 	}
 
-	var callOpts callOptions
-	if len(opts) > 0 {
-		for _, opt := range opts {
-			opt.apply(&callOpts)
+	callOpts := newCallOptions(opts)
+	callOpts.method = method
+
+	var idRaw json.RawMessage
+	if len(callOpts.Hooks) > 0 {
+		callOpts.idRaw = &idRaw
+	}
+
+	ctx, cancel := client.withTimeout(ctx, url, callOpts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := client.withCircuit(url, func() error {
+		return contextError(ctx, client.invoke(ctx, url, method, params, result, callOpts))
+	})
+	err = client.queueOffline(url, method, params, false, err, callOpts)
+	d := time.Since(start)
+	client.recordHost(url, d, err)
+	client.metrics.recordCall(err)
+	callOpts.runHooks(ctx, CallInfo{
+		Method:   method,
+		Duration: d,
+		Err:      err,
+		IDRaw:    idRaw,
+	})
+
+	return err
+}
+
+// invoke calls the method with retries, and waits for the artificial latency.
+func (client *Client) invoke(ctx context.Context, url string, method string, params interface{}, result interface{}, callOpts callOptions) error {
+	if enc, ok := params.(ParamsEncoder); ok {
+		b, known, err := enc.ParamsBytes()
+		if err != nil {
+			return fmt.Errorf("failed to encode params: %w", err)
+		}
+		if !known {
+			return client.callStream(ctx, url, method, enc.WriteParams, result, callOpts)
+		}
+		params = json.RawMessage(b)
+	}
+
+	var cacheKey string
+	if callOpts.ResultCache != nil {
+		key, err := resultCacheKey(client.endpoint(url), method, params, callOpts)
+		if err != nil {
+			return err
+		}
+		if raw, ok := callOpts.ResultCache.get(key, client.clockOrDefault().Now()); ok {
+			if callOpts.stats != nil {
+				*callOpts.stats = CallStats{FromCache: true}
+			}
+			if callOpts.ExpectNoResult {
+				return nil
+			}
+			return decodeResult(raw, result, callOpts)
 		}
+		cacheKey = key
 	}
 
-	id, body, err := requestBody(method, params)
+	id, body, err := requestBody(method, params, callOpts)
 	if err != nil {
 		return err
 	}
+	if err := callOpts.checkRequestSize(body); err != nil {
+		return err
+	}
 
-	req, err := client.newRequest(ctx, url, body, callOpts)
+	var scratch callScratch
+	err = client.retry(ctx, callOpts, func(attempt int) error {
+		if attempt > 1 && callOpts.FreshIDOnRetry {
+			id, body, err = requestBody(method, params, callOpts)
+			if err != nil {
+				return err
+			}
+		}
+
+		if callOpts.HedgeDelay > 0 && callOpts.MaxHedges > 0 {
+			id, err = client.hedge(ctx, url, method, params, id, body, result, &scratch, callOpts)
+			return err
+		}
+
+		return client.call(ctx, url, id, bytes.NewReader(body), result, &scratch, callOpts)
+	})
+	callOpts.recordIDRaw(id)
+	err = callOpts.checkCorrelationID(scratch.detach(err))
+	callOpts.audit(ctx, body, &scratch, err)
+	if err == nil && callOpts.ResultCache != nil && len(scratch.res.Result) > 0 {
+		callOpts.ResultCache.put(cacheKey, scratch.res.Result, client.clockOrDefault().Now())
+	}
 	if err != nil {
+		if ok, serr := client.serveStale(ctx, cacheKey, result, err, callOpts); ok {
+			err = serr
+		}
+	}
+
+	if err := sleepContext(ctx, callOpts.ArtificialLatency); err != nil {
 		return err
 	}
 
-	res, err := client.httpClient().Do(req)
+	return err
+}
+
+// call sends the request body to the url once, and stores the result in the result.
+// The response is decoded into the scratch, which is reused across the attempts of the call.
+func (client *Client) call(ctx context.Context, url string, id requestID, body io.Reader, result interface{}, scratch *callScratch, callOpts callOptions) error {
+	scratch.audit = nil
+
+	res, err := client.post(ctx, url, body, callOpts)
 	if err != nil {
-		return fmt.Errorf("failed to post request: %w", err)
+		return err
 	}
-	defer res.Body.Close()
-	defer io.Copy(ioutil.Discard, res.Body)
+	defer closeBody(res.Body)
 
-	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("server does not respond 200 OK: %s", res.Status)
-	}
+	rpcRes := scratch.reset()
 
-	var rpcRes response
+	resBody, err := callOpts.auditBody(res.Body, scratch)
+	if err != nil {
+		return err
+	}
 
-	if err := json.NewDecoder(res.Body).Decode(&rpcRes); err != nil {
+	raw, err := decodeResponse(resBody, rpcRes)
+	if err != nil {
+		if res.StatusCode != http.StatusOK && errors.Is(err, io.EOF) {
+			// the server accepts the request with a status such as 202 Accepted, but without a result.
+			return nil
+		}
+		if perr := checkEmptyBody(err); perr != nil {
+			return perr
+		}
 		return fmt.Errorf("failed to decode response JSON: %w", err)
 	}
 
-	if rpcRes.Error != nil {
-		return rpcRes.Error
+	if err := checkVersion(rpcRes.JSONRPC); err != nil {
+		return err
 	}
 
-	if rpcRes.ID != id {
-		return errors.New("response ID is not matched to request")
+	if err := callOpts.extractWarnings(raw); err != nil {
+		return err
 	}
 
-	if err := json.Unmarshal([]byte(rpcRes.Result), result); err != nil {
-		return fmt.Errorf("failed to decode result JSON: %w", err)
+	if !isNullJSON(rpcRes.Error) {
+		if err := json.Unmarshal(rpcRes.Error, &scratch.err); err != nil {
+			return fmt.Errorf("failed to decode response JSON: %w", err)
+		}
+		callOpts.mapErrorCode(&scratch.err)
+		client.decodeErrorData(&scratch.err)
+		callOpts.recordEnvelope(rpcRes, &scratch.err)
+		return &scratch.err
+	}
+
+	if err := callOpts.checkResponseID(rpcRes.ID, id); err != nil {
+		return err
+	}
+	callOpts.recordEnvelope(rpcRes, nil)
+
+	if callOpts.ExpectNoResult {
+		if !isNullJSON(rpcRes.Result) {
+			return &UnexpectedResultError{Result: rpcRes.Result}
+		}
+		return nil
+	}
+
+	return decodeResult(rpcRes.Result, result, callOpts)
+}
+
+// checkResponseID returns an error if the raw id of a successful response is not the id of the request.
+func (opts *callOptions) checkResponseID(raw json.RawMessage, id requestID) error {
+	if opts.StrictResponseID && isNullJSON(raw) {
+		return &ProtocolError{
+			Kind:    NullID,
+			Message: "server responds a successful result with a null id",
+		}
+	}
+
+	var resID requestID
+	if !isNullJSON(raw) {
+		var err error
+		if resID, err = parseRequestID(raw); err != nil {
+			return fmt.Errorf("failed to decode response JSON: %w", err)
+		}
+	}
+	if resID != id {
+		return errors.New("response ID is not matched to request")
 	}
 
 	return nil
 }
 
-func (client *Client) newRequest(ctx context.Context, url string, body io.Reader, opts callOptions) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+// callScratch holds the values reused across the attempts of a call to reduce allocations.
+type callScratch struct {
+	res   rawErrorResponse
+	err   ResponseError
+	audit []byte
+}
+
+// rawErrorResponse is a response to a single request with the error and the id left undecoded,
+// so that the error is decoded into the ResponseError of the callScratch only if it is present,
+// and a null id can be distinguished.
+type rawErrorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   json.RawMessage `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// reset resets the response of the scratch keeping its buffers, and returns it.
+func (scratch *callScratch) reset() *rawErrorResponse {
+	scratch.res = rawErrorResponse{
+		Result: scratch.res.Result[:0],
+		Error:  scratch.res.Error[:0],
+		ID:     scratch.res.ID[:0],
+	}
+
+	return &scratch.res
+}
+
+// detach returns a copy of the ResponseError of the scratch if err is it,
+// so that the returned error does not keep the scratch alive. Otherwise it returns err as is.
+func (scratch *callScratch) detach(err error) error {
+	if err != &scratch.err {
+		return err
+	}
+
+	rpcErr := scratch.err
+	return &rpcErr
+}
+
+func newCallOptions(opts []Option) callOptions {
+	var callOpts callOptions
+	for _, opt := range opts {
+		opt.apply(&callOpts)
+	}
+
+	return callOpts
+}
+
+// post posts the body to the url, and returns the response if the server responds 200 OK,
+// or a status accepted by AcceptStatus or WithAcceptStatus.
+// The response body must be closed by closeBody.
+func (client *Client) post(ctx context.Context, url string, body io.Reader, callOpts callOptions) (*http.Response, error) {
+	res, err := client.do(ctx, url, body, callOpts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new HTTP request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Add("Content-Type", "text/json")
+	if callOpts.HTTPVersion == http2 && res.ProtoMajor != 2 {
+		closeBody(res.Body)
+		return nil, fmt.Errorf("server does not respond with HTTP/2: %s", res.Proto)
+	}
 
-	if opts.Header != nil {
-		for key, values := range opts.Header {
-			for _, value := range values {
-				req.Header.Add(key, value)
-			}
+	if err := callOpts.checkServerHeader(res); err != nil {
+		closeBody(res.Body)
+		return nil, err
+	}
+
+	if isRedirect(res.StatusCode) && res.Header.Get("Location") == "" {
+		closeBody(res.Body)
+		return nil, &InvalidRedirectError{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
 		}
 	}
 
-	return req, nil
+	if !client.acceptStatus(res.StatusCode, callOpts) {
+		closeBody(res.Body)
+		return nil, &StatusError{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+		}
+	}
+
+	if callOpts.BodyReadTimeout > 0 {
+		res.Body = newTimeoutBody(res.Body, callOpts.BodyReadTimeout)
+	}
+
+	if err := callOpts.verifyResponse(res); err != nil {
+		closeBody(res.Body)
+		return nil, err
+	}
+
+	if err := callOpts.unwrapResponseEnvelope(res); err != nil {
+		closeBody(res.Body)
+		return nil, err
+	}
+
+	if err := callOpts.rewriteResponse(res); err != nil {
+		closeBody(res.Body)
+		return nil, err
+	}
+
+	return res, nil
 }
 
-func (client *Client) httpClient() *http.Client {
-	if client.HTTPClient == nil {
-		return http.DefaultClient
+// do posts the body to the url, and returns the response whatever the status is.
+// The body is sent with Content-Length if it is a *bytes.Reader, otherwise it is streamed.
+func (client *Client) do(ctx context.Context, url string, body io.Reader, callOpts callOptions) (*http.Response, error) {
+	if callOpts.TransferBudget > 0 {
+		sent, recv := client.Transferred()
+		if sent+recv >= callOpts.TransferBudget {
+			return nil, &BudgetExceededError{
+				Budget:      callOpts.TransferBudget,
+				Transferred: sent + recv,
+			}
+		}
 	}
 
-	return client.HTTPClient
+	if err := callOpts.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := client.sleep(ctx, callOpts.ArtificialDelay); err != nil {
+		return nil, err
+	}
+
+	stats := callOpts.stats
+	if stats != nil {
+		*stats = CallStats{}
+		ctx = traceConn(ctx, stats)
+	}
+
+	countSent := func(n int64) {
+		client.addTransferred(n, 0)
+		if stats != nil {
+			atomic.AddInt64(&stats.RequestBytes, n)
+		}
+	}
+	// The request body is counted once the transport has written it,
+	// so that the requests failing to connect do not consume the transfer budget.
+	if b, ok := body.(*bytes.Reader); ok {
+		n := int64(b.Len())
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			WroteRequest: func(info httptrace.WroteRequestInfo) {
+				if info.Err == nil {
+					countSent(n)
+				}
+			},
+		})
+	} else {
+		body = &countingReader{r: body, count: countSent}
+	}
+
+	req, err := client.newRequest(ctx, url, body, callOpts)
+	if err != nil {
+		return nil, err
+	}
+	callOpts.dumpCurl(req)
+	callOpts.trackUpload(req)
+
+	httpClient, err := client.httpClientFor(callOpts.HTTPVersion)
+	if err != nil {
+		return nil, err
+	}
+	if callOpts.httpClient != nil && callOpts.HTTPVersion == httpAuto {
+		httpClient = callOpts.httpClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post request: %w", err)
+	}
+	client.learnedTimeouts.learn(req.URL.Host, res.Header)
+	res.Body = &countingBody{rc: res.Body, count: func(n int64) {
+		client.addTransferred(0, n)
+		if stats != nil {
+			stats.ResponseWireBytes += n
+		}
+	}}
+
+	if strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		res.Body = &gzipBody{rc: res.Body}
+		res.Header.Del("Content-Encoding")
+		res.Header.Del("Content-Length")
+		res.ContentLength = -1
+		res.Uncompressed = true
+	} else if callOpts.ForceGzipResponse && res.Header.Get("Content-Encoding") == "" {
+		res.Body = &sniffGzipBody{rc: res.Body}
+		res.Header.Del("Content-Length")
+		res.ContentLength = -1
+	}
+
+	if stats != nil {
+		res.Body = &countingBody{rc: res.Body, count: func(n int64) {
+			stats.ResponseBytes += n
+		}}
+	}
+
+	if callOpts.ReplayLog != nil {
+		res.Body = callOpts.ReplayLog.wrap(req, res)
+	}
+
+	return res, nil
 }
 
-type callOptions struct {
-	Header http.Header
+// StatusError is returned when the server does not respond 200 OK.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response, e.g. 503.
+	StatusCode int `json:"statusCode"`
+	// Status is the HTTP status of the response, e.g. "503 Service Unavailable".
+	Status string `json:"status"`
 }
 
-// Option represents an option used to method calling.
-type Option interface {
-	apply(opts *callOptions)
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("server does not respond 200 OK: %s", err.Status)
 }
 
-type optionFunc func(opts *callOptions)
+// InvalidRedirectError is returned when the server responds a redirect without the Location header.
+// It usually means the server is misconfigured.
+type InvalidRedirectError struct {
+	// StatusCode is the HTTP status code of the response, e.g. 302.
+	StatusCode int `json:"statusCode"`
+	// Status is the HTTP status of the response, e.g. "302 Found".
+	Status string `json:"status"`
+}
 
-func (f optionFunc) apply(opts *callOptions) {
-	f(opts)
+func (err *InvalidRedirectError) Error() string {
+	return fmt.Sprintf("server responds a redirect without Location: %s", err.Status)
 }
 
-func WithHeader(header http.Header) Option {
-	return optionFunc(func(opts *callOptions) {
-		opts.Header = header
+// isRedirect reports whether code is a status code the HTTP client follows as a redirect.
+func isRedirect(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// closeBody drains and closes the body so that the connection can be reused.
+func closeBody(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
+
+// decodeResponse decodes a response to a single request from r, and returns the raw response object.
+// A one-element array is also accepted, as some servers respond to a single request in the batch form.
+func decodeResponse(r io.Reader, rpcRes *rawErrorResponse) (json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '[' {
+		var rpcResList []json.RawMessage
+		if err := json.Unmarshal(raw, &rpcResList); err != nil {
+			return nil, err
+		}
+		if len(rpcResList) != 1 || isNullJSON(rpcResList[0]) {
+			return nil, fmt.Errorf("server responds %d responses to a single request", len(rpcResList))
+		}
+
+		raw = rpcResList[0]
+	}
+
+	return raw, json.Unmarshal(raw, rpcRes)
+}
+
+// ResultScanner decodes the result by itself.
+// If the result passed to Call implements ResultScanner, ScanRPC is called with the raw result
+// instead of decoding it by json.Unmarshal or the unmarshaler given by WithResultUnmarshaler.
+type ResultScanner interface {
+	// ScanRPC decodes the raw result.
+	ScanRPC(data json.RawMessage) error
+}
+
+func decodeResult(raw json.RawMessage, result interface{}, opts callOptions) error {
+	if scanner, ok := result.(ResultScanner); ok {
+		if err := scanner.ScanRPC(raw); err != nil {
+			return fmt.Errorf("failed to scan result: %w", err)
+		}
+		return nil
+	}
+
+	if opts.ResultUnmarshaler != nil {
+		if err := opts.ResultUnmarshaler(raw, result); err != nil {
+			return fmt.Errorf("failed to decode result JSON: %w", err)
+		}
+		return nil
+	}
+
+	if !opts.DisallowUnknownResultFields {
+		if err := json.Unmarshal(raw, result); err != nil {
+			return fmt.Errorf("failed to decode result JSON: %w", err)
+		}
+		return nil
+	}
+
+	// the decoder is used only to disallow unknown fields, so it must reject what json.Unmarshal rejects.
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(result); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("failed to decode result JSON: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("failed to decode result JSON: invalid data after top-level value")
+	}
+
+	return nil
+}
+
+// isNullJSON reports whether raw is empty or JSON null.
+func isNullJSON(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+// UnexpectedResultError is returned when the server responds a non-null result
+// for the method expected to return no result.
+type UnexpectedResultError struct {
+	// Result is the result responded by the server.
+	Result json.RawMessage `json:"result"`
+}
+
+func (err *UnexpectedResultError) Error() string {
+	return fmt.Sprintf("server responds a result for the method expected to return no result: %s", err.Result)
+}
+
+func (client *Client) newRequest(ctx context.Context, url string, body io.Reader, opts callOptions) (*http.Request, error) {
+	url = client.endpoint(url)
+
+	var req *http.Request
+	var err error
+	if opts.GETBatch && opts.batchSize > 0 {
+		req, err = newGETBatchRequest(ctx, url, body)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new HTTP request: %w", err)
+	}
+
+	if req.Method == http.MethodPost {
+		charset := opts.ContentTypeCharset
+		if charset == "" {
+			charset = defaultCharset
+		}
+		req.Header.Add("Content-Type", "application/json; charset="+charset)
+	}
+
+	for key, values := range client.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	for key, values := range client.hostHeader(req.URL.Host, req.URL.Hostname()) {
+		if _, ok := opts.Header[key]; ok {
+			continue
+		}
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	if opts.Header != nil {
+		for key, values := range opts.Header {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+
+	if opts.CorrelationID != "" {
+		req.Header.Set(client.correlationHeader(), opts.CorrelationID)
+	}
+
+	if maxAge, ok := opts.CacheControl[opts.method]; ok && req.Header.Get("Cache-Control") == "" {
+		req.Header.Set("Cache-Control", fmt.Sprintf("max-age=%d", int64(maxAge/time.Second)))
+	}
+
+	// request compression explicitly instead of the transport,
+	// so that the size of the response body on the wire can be counted.
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	return req, nil
+}
+
+func (client *Client) httpClient() *http.Client {
+	if client.HTTPClient == nil {
+		return http.DefaultClient
+	}
+
+	return client.HTTPClient
+}
+
+func (client *Client) httpClientFor(version httpVersion) (*http.Client, error) {
+	if version == httpAuto {
+		return client.httpClient(), nil
+	}
+
+	client.forcedOnce.Do(client.initForcedClients)
+	if client.forcedErr != nil {
+		return nil, client.forcedErr
+	}
+
+	if version == http1 {
+		return client.http1Client, nil
+	}
+
+	return client.http2Client, nil
+}
+
+// initForcedClients creates HTTP clients that force HTTP/1.1 or HTTP/2
+// from the transport of the client.
+func (client *Client) initForcedClients() {
+	base := client.httpClient()
+
+	rt := base.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		client.forcedErr = errors.New("HTTP version cannot be forced with a transport other than *http.Transport")
+		return
+	}
+
+	t1 := transport.Clone()
+	t1.ForceAttemptHTTP2 = false
+	t1.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	if t1.TLSClientConfig == nil {
+		t1.TLSClientConfig = &tls.Config{}
+	}
+	t1.TLSClientConfig.NextProtos = []string{"http/1.1"}
+
+	t2 := transport.Clone()
+	t2.ForceAttemptHTTP2 = true
+	t2.TLSNextProto = nil
+
+	c1 := *base
+	c1.Transport = t1
+	client.http1Client = &c1
+
+	c2 := *base
+	c2.Transport = t2
+	client.http2Client = &c2
+}
+
+// Transferred returns the total bytes of request bodies sent and
+// response bodies received by the client.
+// A request body is counted only when it is written to the connection.
+func (client *Client) Transferred() (sent, recv int64) {
+	client.transferMu.Lock()
+	defer client.transferMu.Unlock()
+
+	return client.sent, client.recv
+}
+
+func (client *Client) addTransferred(sent, recv int64) {
+	client.transferMu.Lock()
+	defer client.transferMu.Unlock()
+
+	client.sent += sent
+	client.recv += recv
+}
+
+// countingBody counts bytes read from rc by count.
+type countingBody struct {
+	rc    io.ReadCloser
+	count func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		b.count(int64(n))
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	return b.rc.Close()
+}
+
+// countingReader counts bytes read from r by count.
+type countingReader struct {
+	r     io.Reader
+	count func(n int64)
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.count(int64(n))
+	}
+	return n, err
+}
+
+// gzipBody decompresses rc by gzip.
+// The gzip header is read on the first read, so that an empty body results in io.EOF.
+type gzipBody struct {
+	rc  io.ReadCloser
+	zr  *gzip.Reader
+	err error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.zr == nil && b.err == nil {
+		b.zr, b.err = gzip.NewReader(b.rc)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	return b.zr.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.rc.Close()
+}
+
+// BudgetExceededError is returned when the transfer budget of the client is exhausted.
+type BudgetExceededError struct {
+	// Budget is the transfer budget in bytes.
+	Budget int64 `json:"budget"`
+	// Transferred is the total bytes transferred by the client.
+	Transferred int64 `json:"transferred"`
+}
+
+func (err *BudgetExceededError) Error() string {
+	return fmt.Sprintf("transfer budget exceeded: %d of %d bytes transferred", err.Transferred, err.Budget)
+}
+
+// RequestTooLargeError is returned when the request body exceeds the size given by WithMaxRequestBytes.
+type RequestTooLargeError struct {
+	// Method is the method name of the request.
+	Method string `json:"method"`
+	// Size is the size of the request body in bytes.
+	Size int64 `json:"size"`
+	// MaxBytes is the max size of the request body in bytes.
+	MaxBytes int64 `json:"maxBytes"`
+}
+
+func (err *RequestTooLargeError) Error() string {
+	return fmt.Sprintf("request of %s is too large: %d bytes exceeds %d bytes, consider paginating the params", err.Method, err.Size, err.MaxBytes)
+}
+
+type httpVersion int
+
+const (
+	httpAuto httpVersion = iota
+	http1
+	http2
+)
+
+type callOptions struct {
+	Header          http.Header
+	HTTPVersion     httpVersion
+	TransferBudget  int64
+	MaxRequestBytes int64
+	ExpectNoResult  bool
+
+	DisallowUnknownResultFields bool
+
+	EnvelopeMarshaler func(method string, params interface{}, id json.RawMessage) ([]byte, error)
+	EnvelopeKeyCase   KeyCase
+	ParamsMarshaler   func(params interface{}) (json.RawMessage, error)
+	ResultUnmarshaler func(raw json.RawMessage, result interface{}) error
+	ParamsTemplate    map[string]interface{}
+	ParamConversion   *OpenRPCDocument
+	EmptyParams       EmptyParamsMode
+
+	MaxRetries    int
+	Backoff       ErrorBackoffFunc
+	RetrySafeOnly bool
+
+	FreshIDOnRetry  bool
+	DuplicateIDCode ErrorCode
+
+	HedgeDelay time.Duration
+	MaxHedges  int
+
+	QueueIfOffline bool
+
+	RetryProtocolErrors []ProtocolErrorKind
+	RetryStatuses       []int
+	RetryErrorCodes     []ErrorCode
+
+	Timeout         time.Duration
+	BodyReadTimeout time.Duration
+
+	ContentTypeCharset string
+	AcceptStatus       []int
+	ForceGzipResponse  bool
+
+	ArtificialLatency time.Duration
+	ArtificialDelay   time.Duration
+
+	RateLimiter       RateLimiter
+	BatchRateLimiting bool
+
+	NotificationDedup   bool
+	StrictNotifications bool
+	GETBatch            bool
+	BatchPersistence    func(batchID string, ids []string, state BatchState)
+	BatchPagination     func(resp BatchResponse) (nextParams interface{}, more bool)
+	ObjectBatchResponse bool
+
+	CorrelationID string
+
+	CurlDump   io.Writer
+	CurlRedact []string
+	ReplayLog  *replayLog
+
+	UploadProgress func(bytesSent, totalBytes int64)
+
+	ResultCache *ResultCache
+	MaxStale    time.Duration
+
+	ResponseVerifier func(body []byte, header http.Header) error
+	ResponseRewriter func(raw []byte) ([]byte, error)
+	ResponseEnvelope string
+
+	ExpectServerHeader string
+	ExpectServerValue  string
+
+	WarningsField string
+	ErrorTrailer  string
+
+	AuditSink     AuditSink
+	AuditFailures bool
+
+	ErrorCodeMapper  func(code ErrorCode) ErrorCode
+	StrictResponseID bool
+
+	CacheControl map[string]time.Duration
+
+	Hooks []CallHook
+
+	// method is the method name of the call, empty for a batch.
+	method string
+	// batchSize is the number of requests in the batch, set by CallBatch.
+	batchSize int
+	// stats is the statistics of the call, set by CallWithStats.
+	stats *CallStats
+	// idRaw receives the raw id of the request for the hooks, set by Call.
+	idRaw *json.RawMessage
+	// idSequence is the sequence of the request ids, set by WithIDSequence.
+	idSequence *idSequence
+	// envelope receives the whole response, set by CallEnvelope.
+	envelope *Response
+	// offlineFlush reports whether the call is sent by the flush of the offline queue.
+	offlineFlush bool
+	// httpClient is the HTTP client of the endpoint, set by Failover.
+	httpClient *http.Client
+}
+
+// Option represents an option used to method calling.
+type Option interface {
+	apply(opts *callOptions)
+}
+
+type optionFunc func(opts *callOptions)
+
+func (f optionFunc) apply(opts *callOptions) {
+	f(opts)
+}
+
+func WithHeader(header http.Header) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.Header = header
+	})
+}
+
+// WithForceHTTP1 returns an Option that forces the call to use HTTP/1.1.
+func WithForceHTTP1() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.HTTPVersion = http1
+	})
+}
+
+// WithForceHTTP2 returns an Option that forces the call to use HTTP/2.
+// The call fails if the server does not respond with HTTP/2.
+func WithForceHTTP2() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.HTTPVersion = http2
+	})
+}
+
+// WithTransferBudget returns an Option that fails the call with *BudgetExceededError
+// if the total bytes transferred by the client have reached maxBytes.
+func WithTransferBudget(maxBytes int64) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.TransferBudget = maxBytes
+	})
+}
+
+// WithMaxRequestBytes returns an Option that fails the call with *RequestTooLargeError
+// before sending it, if the request body is larger than maxBytes,
+// instead of the server rejecting it, e.g. with 413 Request Entity Too Large.
+// It is not applied to the params streamed by a ParamsEncoder.
+func WithMaxRequestBytes(maxBytes int64) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.MaxRequestBytes = maxBytes
+	})
+}
+
+// checkRequestSize returns a RequestTooLargeError if body is larger than the max request bytes.
+func (opts *callOptions) checkRequestSize(body []byte) error {
+	if opts.MaxRequestBytes > 0 && int64(len(body)) > opts.MaxRequestBytes {
+		return &RequestTooLargeError{
+			Method:   opts.method,
+			Size:     int64(len(body)),
+			MaxBytes: opts.MaxRequestBytes,
+		}
+	}
+
+	return nil
+}
+
+// WithExpectNoResult returns an Option that expects the method to return no result.
+// The call fails with *UnexpectedResultError if the server responds a non-null result.
+// The result passed to Call is not used and may be nil.
+func WithExpectNoResult() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ExpectNoResult = true
+	})
+}
+
+// WithDisallowUnknownResultFields returns an Option that fails the call
+// if the result contains object keys which do not match any non-ignored,
+// exported fields of the result.
+func WithDisallowUnknownResultFields() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.DisallowUnknownResultFields = true
+	})
+}
+
+// WithEnvelopeMarshaler returns an Option that marshals the whole request by marshal.
+// marshal receives the method, the params and the JSON encoded request ID,
+// and must return the request body including the ID, so that the response can be matched.
+// The response is decoded as a standard JSON-RPC 2.0 response.
+func WithEnvelopeMarshaler(marshal func(method string, params interface{}, id json.RawMessage) ([]byte, error)) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.EnvelopeMarshaler = marshal
+	})
+}
+
+// WithEnvelopeKeyCase returns an Option that sends the request with the keys of the envelope in the style.
+// This is for servers which do not conform to the JSON-RPC 2.0 specification.
+func WithEnvelopeKeyCase(style KeyCase) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.EnvelopeKeyCase = style
+	})
+}
+
+// WithCacheControl returns an Option that sends the Cache-Control header with max-age of maxAge
+// when the method is called, so that caching proxies can cache the response of read methods.
+// maxAge is truncated to seconds. The header is not sent for batch calls.
+// WithCacheControl can be given multiple times to configure multiple methods.
+func WithCacheControl(method string, maxAge time.Duration) Option {
+	return optionFunc(func(opts *callOptions) {
+		if opts.CacheControl == nil {
+			opts.CacheControl = make(map[string]time.Duration)
+		}
+		opts.CacheControl[method] = maxAge
+	})
+}
+
+// WithErrorCodeMapper returns an Option that maps the code of an error responded by the server by mapper,
+// e.g. to normalize custom codes of a server to the standard ones.
+// The code before mapping is kept in ResponseError.OriginalCode.
+// The mapped code is used to decide whether the call is retried.
+func WithErrorCodeMapper(mapper func(code ErrorCode) ErrorCode) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ErrorCodeMapper = mapper
+	})
+}
+
+// WithStrictResponseID returns an Option that makes the call fail with a ProtocolError of NullID
+// if the server responds a successful result with a null id, which indicates a bug of the server.
+// An error response may have a null id, e.g. to an unparseable request, so it is not validated.
+func WithStrictResponseID() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.StrictResponseID = true
+	})
+}
+
+// mapErrorCode maps the code of err by the ErrorCodeMapper if it is set.
+func (opts *callOptions) mapErrorCode(err *ResponseError) {
+	if opts.ErrorCodeMapper == nil || err == nil {
+		return
+	}
+
+	err.OriginalCode = err.Code
+	err.Code = opts.ErrorCodeMapper(err.Code)
+}
+
+// WithParamsMarshaler returns an Option that marshals params by marshaler instead of json.Marshal,
+// e.g. to project types which are not natively marshalable, such as protocol buffers, into JSON.
+// It is not applied to nil params, and params already encoded, i.e. json.RawMessage and ParamsEncoder.
+func WithParamsMarshaler(marshaler func(params interface{}) (json.RawMessage, error)) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ParamsMarshaler = marshaler
+	})
+}
+
+// WithResultUnmarshaler returns an Option that decodes the result by unmarshaler instead of encoding/json,
+// e.g. to decode the result into types which are not natively unmarshalable, such as protocol buffers.
+// DisallowUnknownResultFields is not applied to the result decoded by unmarshaler.
+func WithResultUnmarshaler(unmarshaler func(raw json.RawMessage, result interface{}) error) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ResultUnmarshaler = unmarshaler
+	})
+}
+
+// WithParamsTemplate returns an Option that merges the fields of template into the params of every call,
+// e.g. to send common fields such as an API version.
+// The fields of the params override the ones of template with the same name.
+// It is applied only if the params marshal to an object, and not applied to the params streamed by a ParamsEncoder.
+func WithParamsTemplate(template map[string]interface{}) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ParamsTemplate = template
+	})
+}
+
+// marshalParams encodes params of the method by the ParamsEncoder or marshals them by the ParamsMarshaler, merges the ParamsTemplate,
+// converts them by the ParamConversion and applies the EmptyParams mode if they are set,
+// otherwise returns params as is.
+func (opts *callOptions) marshalParams(method string, params interface{}) (interface{}, error) {
+	if params == nil {
+		return params, nil
+	}
+
+	params, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, encoded := params.(json.RawMessage)
+	if opts.ParamsMarshaler != nil && !encoded {
+		b, err := opts.ParamsMarshaler(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		params, raw = b, b
+	}
+
+	conversion := opts.ParamConversion.positional(method)
+	if len(opts.ParamsTemplate) == 0 && conversion == nil && opts.EmptyParams == 0 {
+		return params, nil
+	}
+
+	if raw == nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		raw = b
+	}
+
+	if len(opts.ParamsTemplate) > 0 {
+		b, err := mergeParamsTemplate(raw, opts.ParamsTemplate)
+		if err != nil {
+			return nil, err
+		}
+		raw = b
+	}
+
+	if conversion != nil {
+		b, err := conversion.toPositional(raw)
+		if err != nil {
+			return nil, err
+		}
+		raw = b
+	}
+
+	return opts.EmptyParams.apply(raw), nil
+}
+
+// mergeParamsTemplate returns raw with the fields of template absent in it, if raw is an object.
+func mergeParamsTemplate(raw json.RawMessage, template map[string]interface{}) (json.RawMessage, error) {
+	if b := bytes.TrimSpace(raw); len(b) == 0 || b[0] != '{' {
+		return raw, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode params: %w", err)
+	}
+
+	merged := make(map[string]interface{}, len(template)+len(fields))
+	for name, value := range template {
+		merged[name] = value
+	}
+	for name, value := range fields {
+		merged[name] = value
+	}
+
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	return b, nil
+}
+
+// WithTimeout returns an Option that limits the time of the call, including retries, to d.
+// The context of the call derived with the deadline is passed to the hooks given by WithCallHook,
+// so that they can see the deadline by ctx.Deadline.
+// It is applied by Call and CallBatch.
+func WithTimeout(d time.Duration) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.Timeout = d
+	})
+}
+
+// WithBodyReadTimeout returns an Option that fails the call with ErrBodyReadTimeout
+// if a read of the response body is blocked longer than d.
+// The timeout is reset on each read, so it bounds stalls rather than the total read time.
+func WithBodyReadTimeout(d time.Duration) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.BodyReadTimeout = d
+	})
+}
+
+// WithAcceptStatus returns an Option that accepts the responses of the HTTP status codes as success
+// in addition to 200 OK, instead of the AcceptStatus of the Client,
+// e.g. 202 Accepted for a method processed asynchronously.
+// WithAcceptStatus without codes accepts only 200 OK.
+// It is also applied to Notify, which accepts 204 No Content as well.
+// A response of an accepted status other than 200 OK may have an empty body,
+// then the call succeeds without storing the result.
+func WithAcceptStatus(codes ...int) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.AcceptStatus = append([]int{}, codes...)
+	})
+}
+
+// acceptStatus reports whether the response of the status code is accepted as success.
+func (client *Client) acceptStatus(code int, opts callOptions) bool {
+	if code == http.StatusOK {
+		return true
+	}
+
+	accepted := client.AcceptStatus
+	if opts.AcceptStatus != nil {
+		accepted = opts.AcceptStatus
+	}
+	for _, c := range accepted {
+		if code == c {
+			return true
+		}
+	}
+	return false
+}
+
+// WithContentTypeCharset returns an Option that sets the charset parameter of the Content-Type header.
+// The default Content-Type is "application/json; charset=utf-8".
+func WithContentTypeCharset(charset string) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ContentTypeCharset = charset
+	})
+}
+
+// WithArtificialDelay returns an Option that delays sending each request of the call by d,
+// measured by the clock of the Client, to simulate a slow server.
+// It fails the call with the error of ctx if ctx is done during the delay.
+//
+// This is only for testing timeouts of the callers, it must not be used in production.
+func WithArtificialDelay(d time.Duration) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ArtificialDelay = d
+	})
+}
+
+// WithArtificialLatency returns an Option that delays returning from the call by d.
+// It fails the call with the error of ctx if ctx is done during the delay.
+//
+// This is only for testing timeouts and loading states against a local server,
+// it must not be used in production.
+func WithArtificialLatency(d time.Duration) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ArtificialLatency = d
+	})
+}
+
+type clientOptions struct {
+	TCPKeepAlive    time.Duration
+	IdleConnTimeout time.Duration
+	MaxConnsPerHost int
+	ResultTypes     map[string]interface{}
+	ErrorDataTypes  map[ErrorCode]interface{}
+	Clock           Clock
+	HostHeaders     map[string]http.Header
+
+	CircuitThreshold     int
+	CircuitCooldown      time.Duration
+	CircuitStateCallback func(host string, from, to CircuitState)
+
+	RetryConcurrency int
+
+	PinnedCertSHA256 []string
+
+	ProxyURL  *url.URL
+	ProxyUser *url.Userinfo
+
+	LearnedTimeoutHeader string
+	LearnedTimeoutCap    time.Duration
+
+	MaxSubscriptions int
+
+	OfflineQueue QueueStore
+}
+
+// tunesTransport reports whether any option tuning the transport is set.
+func (opts *clientOptions) tunesTransport() bool {
+	return opts.TCPKeepAlive != 0 || opts.IdleConnTimeout != 0 || opts.MaxConnsPerHost != 0 ||
+		len(opts.PinnedCertSHA256) > 0 || opts.ProxyURL != nil
+}
+
+// dialer returns a dialer for the transport, based on the dialer of http.DefaultTransport.
+func (opts *clientOptions) dialer() *net.Dialer {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if opts.TCPKeepAlive != 0 {
+		dialer.KeepAlive = opts.TCPKeepAlive
+	}
+
+	return dialer
+}
+
+// transport returns a transport cloned from http.DefaultTransport and tuned by the options.
+func (opts *clientOptions) transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = opts.dialer().DialContext
+	if opts.IdleConnTimeout != 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if opts.MaxConnsPerHost != 0 {
+		transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+	if proxy := opts.proxy(); proxy != nil {
+		transport.Proxy = proxy
+	}
+	if len(opts.PinnedCertSHA256) > 0 {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.VerifyConnection = verifyPinnedCert(opts.PinnedCertSHA256)
+	}
+
+	return transport
+}
+
+// ClientOption represents an option used to create a Client by NewClient.
+type ClientOption interface {
+	applyClient(opts *clientOptions)
+}
+
+type clientOptionFunc func(opts *clientOptions)
+
+func (f clientOptionFunc) applyClient(opts *clientOptions) {
+	f(opts)
+}
+
+// WithTCPKeepAlive returns a ClientOption that sets the interval of TCP keep-alive probes.
+// Keep-alive is disabled if d is negative.
+func WithTCPKeepAlive(d time.Duration) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.TCPKeepAlive = d
+	})
+}
+
+// WithHostHeaders returns a ClientOption that sets the headers sent with the requests to each host,
+// keyed by the host of the url with or without the port, e.g. "api.example.com" or "api.example.com:8443".
+// The key with the port takes precedence.
+// The headers replace the same ones of Client.Header, and are overridden by the ones given by WithHeader.
+func WithHostHeaders(headers map[string]http.Header) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		if opts.HostHeaders == nil {
+			opts.HostHeaders = make(map[string]http.Header)
+		}
+		for host, header := range headers {
+			opts.HostHeaders[strings.ToLower(host)] = header.Clone()
+		}
+	})
+}
+
+// hostHeader returns the header for the host given by WithHostHeaders.
+// host may have the port, and hostname is the host without the port.
+func (client *Client) hostHeader(host, hostname string) http.Header {
+	if len(client.hostHeaders) == 0 {
+		return nil
+	}
+
+	if header, ok := client.hostHeaders[strings.ToLower(host)]; ok {
+		return header
+	}
+
+	return client.hostHeaders[strings.ToLower(hostname)]
+}
+
+// WithIdleConnTimeout returns a ClientOption that sets the maximum amount of time
+// an idle connection remains in the pool before closing itself.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.IdleConnTimeout = d
+	})
+}
+
+// WithMaxConnsPerHost returns a ClientOption that limits the number of the connections to each host to n,
+// including the ones dialing, active and idle, regardless of the number of the concurrent calls.
+// The calls exceeding the limit wait for a connection to be available.
+// It is not applied if the HTTPClient of the Client is replaced.
+func WithMaxConnsPerHost(n int) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.MaxConnsPerHost = n
 	})
 }
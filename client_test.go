@@ -1,8 +1,18 @@
 package jsonrpc
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -34,3 +44,944 @@ func TestNewClientWithCustomHTTPClient(t *testing.T) {
 		t.Error("Client.httpClient() does not return valid http.Client")
 	}
 }
+
+type testRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type testResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *ResponseError  `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcHandler returns a handler that responds the result of fn to each request.
+// It responds an array if the request is a batch, except to the notifications in it.
+func rpcHandler(t *testing.T, fn func(req *testRequest) (interface{}, *ResponseError)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		respond := func(req *testRequest) *testResponse {
+			result, rpcErr := fn(req)
+			return &testResponse{
+				JSONRPC: Version,
+				Result:  result,
+				Error:   rpcErr,
+				ID:      req.ID,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if raw[0] == '[' {
+			var reqs []*testRequest
+			if err := json.Unmarshal(raw, &reqs); err != nil {
+				t.Errorf("failed to decode batch request: %v", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			resps := make([]*testResponse, 0, len(reqs))
+			for _, req := range reqs {
+				resp := respond(req)
+				if req.ID == nil {
+					// notification
+					continue
+				}
+				resps = append(resps, resp)
+			}
+			if len(resps) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+
+		var req testRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(respond(&req))
+	})
+}
+
+func TestClientCallForceHTTPVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(protoHandler())
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := &Client{
+		HTTPClient: server.Client(),
+	}
+
+	tests := map[string]struct {
+		opts []Option
+		want string
+	}{
+		"Default": {nil, "HTTP/2.0"},
+		"HTTP1":   {[]Option{WithForceHTTP1()}, "HTTP/1.1"},
+		"HTTP2":   {[]Option{WithForceHTTP2()}, "HTTP/2.0"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var proto string
+			err := client.Call(context.Background(), server.URL, "proto", nil, &proto, tt.opts...)
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if proto != tt.want {
+				t.Errorf("negotiated protocol got %s, want %s", proto, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCallForceHTTP2WithoutSupport(t *testing.T) {
+	server := httptest.NewServer(protoHandler())
+	defer server.Close()
+
+	client := &Client{}
+
+	var result interface{}
+	err := client.Call(context.Background(), server.URL, "proto", nil, &result, WithForceHTTP2())
+	if err == nil {
+		t.Error("Client.Call() must fail when the server does not support HTTP/2")
+	}
+}
+
+// protoHandler responds the protocol of the request as the result.
+func protoHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(&testResponse{
+			JSONRPC: Version,
+			Result:  r.Proto,
+			ID:      req.ID,
+		})
+	})
+}
+
+func TestClientTransferBudget(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		calls++
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "first", nil, &result); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	sent, recv := client.Transferred()
+	if sent == 0 || recv == 0 {
+		t.Fatalf("Client.Transferred() got (%d, %d), want non-zero values", sent, recv)
+	}
+
+	err := client.Call(context.Background(), server.URL, "second", nil, &result, WithTransferBudget(sent+recv))
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("Client.Call() error got %v, want *BudgetExceededError", err)
+	}
+	if budgetErr.Transferred != sent+recv {
+		t.Errorf("BudgetExceededError.Transferred got %d, want %d", budgetErr.Transferred, sent+recv)
+	}
+	if calls != 1 {
+		t.Errorf("server got %d calls, want 1", calls)
+	}
+}
+
+func TestClientTransferBudgetDialFailure(t *testing.T) {
+	client := &Client{
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+				},
+			},
+		},
+	}
+
+	var result string
+	for i := 0; i < 3; i++ {
+		err := client.Call(context.Background(), "http://example.com", "test", []int{1, 2, 3}, &result, WithTransferBudget(1))
+		if err == nil || errors.As(err, new(*BudgetExceededError)) {
+			t.Fatalf("Client.Call() error got %v, want a dial error", err)
+		}
+	}
+
+	if sent, recv := client.Transferred(); sent != 0 || recv != 0 {
+		t.Errorf("Client.Transferred() got (%d, %d), want (0, 0)", sent, recv)
+	}
+}
+
+func TestClientCallMaxRequestBytes(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		calls++
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "small", []int{1}, &result, WithMaxRequestBytes(1024)); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	err := client.Call(context.Background(), server.URL, "large", make([]int, 1024), &result, WithMaxRequestBytes(1024))
+	var tooLargeErr *RequestTooLargeError
+	if !errors.As(err, &tooLargeErr) {
+		t.Fatalf("Client.Call() error got %v, want *RequestTooLargeError", err)
+	}
+	if tooLargeErr.Method != "large" || tooLargeErr.MaxBytes != 1024 || tooLargeErr.Size <= 1024 {
+		t.Errorf("RequestTooLargeError got %+v, want method large exceeding 1024 bytes", tooLargeErr)
+	}
+	if !strings.Contains(err.Error(), "paginat") {
+		t.Errorf("RequestTooLargeError.Error() got %q, want to suggest pagination", err.Error())
+	}
+	if calls != 1 {
+		t.Errorf("server got %d calls, want 1", calls)
+	}
+}
+
+func TestClientTransferredConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	var serverRecv int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		serverRecv += r.ContentLength
+		mu.Unlock()
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	const n = 10
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var result string
+			if err := client.Call(context.Background(), server.URL, "concurrent", nil, &result); err != nil {
+				t.Errorf("Client.Call() failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sent, _ := client.Transferred()
+	if sent != serverRecv {
+		t.Errorf("Client.Transferred() sent got %d, want %d", sent, serverRecv)
+	}
+}
+
+func TestClientCallExpectNoResult(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "void" {
+			return nil, nil
+		}
+		return map[string]int{"count": 1}, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	if err := client.Call(context.Background(), server.URL, "void", nil, nil, WithExpectNoResult()); err != nil {
+		t.Errorf("Client.Call() failed: %v", err)
+	}
+
+	err := client.Call(context.Background(), server.URL, "notVoid", nil, nil, WithExpectNoResult())
+	var resultErr *UnexpectedResultError
+	if !errors.As(err, &resultErr) {
+		t.Fatalf("Client.Call() error got %v, want *UnexpectedResultError", err)
+	}
+	if got, want := string(resultErr.Result), `{"count":1}`; got != want {
+		t.Errorf("UnexpectedResultError.Result got %s, want %s", got, want)
+	}
+}
+
+func TestClientCallDisallowUnknownResultFields(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return map[string]interface{}{
+			"id":    1,
+			"extra": "unexpected",
+		}, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := client.Call(context.Background(), server.URL, "lenient", nil, &result); err != nil {
+		t.Errorf("Client.Call() failed: %v", err)
+	}
+
+	err := client.Call(context.Background(), server.URL, "strict", nil, &result, WithDisallowUnknownResultFields())
+	if err == nil {
+		t.Fatal("Client.Call() must fail with an unknown field")
+	}
+	if !strings.Contains(err.Error(), `"extra"`) {
+		t.Errorf("Client.Call() error %q must name the unknown field", err)
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	tests := map[string]struct {
+		raw     string
+		strict  bool
+		wantErr bool
+	}{
+		"valid":                   {raw: `{"id":1}`},
+		"valid strict":            {raw: `{"id":1}`, strict: true},
+		"trailing garbage":        {raw: `{"id":1} x`, wantErr: true},
+		"trailing garbage strict": {raw: `{"id":1} x`, strict: true, wantErr: true},
+		"trailing value strict":   {raw: `{"id":1}{"id":2}`, strict: true, wantErr: true},
+		"missing":                 {raw: ``, wantErr: true},
+		"missing strict":          {raw: ``, strict: true, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result struct {
+				ID int `json:"id"`
+			}
+			err := decodeResult(json.RawMessage(tt.raw), &result, callOptions{DisallowUnknownResultFields: tt.strict})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeResult() error got %v, want error %v", err, tt.wantErr)
+			}
+			if errors.Is(err, io.EOF) {
+				t.Errorf("decodeResult() error got %v, must not be io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestClientCallEnvelopeMarshaler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Version string          `json:"version"`
+			Action  string          `json:"action"`
+			Args    []int           `json:"args"`
+			ID      json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.Version != "custom" || req.Action != "sum" {
+			t.Errorf("got envelope %+v, want custom envelope", req)
+		}
+
+		sum := 0
+		for _, arg := range req.Args {
+			sum += arg
+		}
+		json.NewEncoder(w).Encode(&testResponse{
+			JSONRPC: Version,
+			Result:  sum,
+			ID:      req.ID,
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	marshal := func(method string, params interface{}, id json.RawMessage) ([]byte, error) {
+		return json.Marshal(map[string]interface{}{
+			"version": "custom",
+			"action":  method,
+			"args":    params,
+			"id":      id,
+		})
+	}
+
+	var sum int
+	err := client.Call(context.Background(), server.URL, "sum", []int{1, 2, 3}, &sum, WithEnvelopeMarshaler(marshal))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if sum != 6 {
+		t.Errorf("Client.Call() result got %d, want 6", sum)
+	}
+}
+
+func TestClientCallEnvelopeKeyCase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		for _, key := range []string{"JSONRPC", "Method", "Params", "ID"} {
+			if _, ok := req[key]; !ok {
+				t.Errorf("request does not have the key %q: %v", key, req)
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"JSONRPC": Version,
+			"Result":  "pascal",
+			"ID":      req["ID"],
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "pascal", []int{1}, &result, WithEnvelopeKeyCase(PascalCase))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if result != "pascal" {
+		t.Errorf("Client.Call() result got %q, want %q", result, "pascal")
+	}
+}
+
+func TestClientCallContentType(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		opts []Option
+		want string
+	}{
+		"Default": {nil, "application/json; charset=utf-8"},
+		"Charset": {[]Option{WithContentTypeCharset("iso-8859-1")}, "application/json; charset=iso-8859-1"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			if err := client.Call(context.Background(), server.URL, "contentType", nil, &result, tt.opts...); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if contentType != tt.want {
+				t.Errorf("Content-Type got %q, want %q", contentType, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCallArtificialLatency(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	const latency = 100 * time.Millisecond
+
+	start := time.Now()
+	var result string
+	if err := client.Call(context.Background(), server.URL, "latency", nil, &result, WithArtificialLatency(latency)); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < latency {
+		t.Errorf("Client.Call() took %v, want at least %v", elapsed, latency)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	err := client.Call(ctx, server.URL, "latency", nil, &result, WithArtificialLatency(time.Minute))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Client.Call() error got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Client.Call() took %v, want to be cancelled", elapsed)
+	}
+}
+
+func TestClientCallWithHostHeaders(t *testing.T) {
+	newServer := func(keys chan<- string) *httptest.Server {
+		handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		})
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys <- r.Header.Get("X-Api-Key")
+			handler.ServeHTTP(w, r)
+		}))
+	}
+
+	keys := make(chan string, 1)
+	server1 := newServer(keys)
+	defer server1.Close()
+	server2 := newServer(keys)
+	defer server2.Close()
+
+	client := NewClient(WithHostHeaders(map[string]http.Header{
+		strings.TrimPrefix(server1.URL, "http://"): {"X-Api-Key": {"key1"}},
+	}))
+
+	override := http.Header{}
+	override.Set("X-Api-Key", "call")
+
+	tests := map[string]struct {
+		url  string
+		opts []Option
+		want string
+	}{
+		"matched":  {url: server1.URL, want: "key1"},
+		"other":    {url: server2.URL, want: ""},
+		"override": {url: server1.URL, opts: []Option{WithHeader(override)}, want: "call"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			if err := client.Call(context.Background(), tt.url, "test", nil, &result, tt.opts...); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if got := <-keys; got != tt.want {
+				t.Errorf("X-Api-Key got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCallArtificialDelay(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		atomic.AddInt32(&calls, 1)
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(WithClock(clock))
+
+	// waitTimer waits until the delay starts.
+	waitTimer := func() {
+		for {
+			clock.mu.Lock()
+			n := len(clock.timers)
+			clock.mu.Unlock()
+			if n > 0 {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		var result string
+		errc <- client.Call(context.Background(), server.URL, "delay", nil, &result, WithArtificialDelay(time.Second))
+	}()
+
+	waitTimer()
+	clock.Advance(999 * time.Millisecond)
+	if calls := atomic.LoadInt32(&calls); calls != 0 {
+		t.Fatalf("server got %d calls before the delay elapses, want 0", calls)
+	}
+	clock.Advance(time.Millisecond)
+	if err := <-errc; err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("server got %d calls, want 1", calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		var result string
+		errc <- client.Call(ctx, server.URL, "delay", nil, &result, WithArtificialDelay(time.Second))
+	}()
+
+	waitTimer()
+	cancel()
+	var ctxErr *ContextError
+	if err := <-errc; !errors.As(err, &ctxErr) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Client.Call() error got %v, want *ContextError wrapping context.Canceled", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("server got %d calls, want 1", calls)
+	}
+}
+
+func TestResponseErrorUnmarshalJSON(t *testing.T) {
+	tests := map[string]struct {
+		data       string
+		code       ErrorCode
+		stringCode string
+	}{
+		"Number":        {`{"code":-32601,"message":"not found"}`, MethodNotFound, ""},
+		"KnownString":   {`{"code":"METHOD_NOT_FOUND","message":"not found"}`, MethodNotFound, "METHOD_NOT_FOUND"},
+		"UnknownString": {`{"code":"NOT_FOUND","message":"not found"}`, 0, "NOT_FOUND"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var rpcErr ResponseError
+			if err := json.Unmarshal([]byte(tt.data), &rpcErr); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if rpcErr.Code != tt.code {
+				t.Errorf("ResponseError.Code got %d, want %d", rpcErr.Code, tt.code)
+			}
+			if rpcErr.StringCode != tt.stringCode {
+				t.Errorf("ResponseError.StringCode got %q, want %q", rpcErr.StringCode, tt.stringCode)
+			}
+			if rpcErr.Message != "not found" {
+				t.Errorf("ResponseError.Message got %q, want %q", rpcErr.Message, "not found")
+			}
+		})
+	}
+}
+
+func TestClientCallStringErrorCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": Version,
+			"error": map[string]interface{}{
+				"code":    "NOT_FOUND",
+				"message": "user not found",
+			},
+			"id": req.ID,
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "user.get", nil, &result)
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Client.Call() error got %v, want *ResponseError", err)
+	}
+	if rpcErr.StringCode != "NOT_FOUND" {
+		t.Errorf("ResponseError.StringCode got %q, want NOT_FOUND", rpcErr.StringCode)
+	}
+	if got, want := rpcErr.Error(), "user not found (NOT_FOUND)"; got != want {
+		t.Errorf("ResponseError.Error() got %q, want %q", got, want)
+	}
+}
+
+func TestClientSharedTransport(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	var dials int32
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	client1 := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	client2 := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+
+	for i, client := range []*Client{client1, client2, client1, client2} {
+		var result string
+		if err := client.Call(context.Background(), server.URL, "shared", nil, &result); err != nil {
+			t.Fatalf("Client.Call() [%d] failed: %v", i, err)
+		}
+	}
+
+	if dials := atomic.LoadInt32(&dials); dials != 1 {
+		t.Errorf("transport got %d dials, want 1", dials)
+	}
+}
+
+func TestNewClientDefault(t *testing.T) {
+	client := NewClient()
+
+	if client.httpClient() != http.DefaultClient {
+		t.Error("Client.httpClient() must be http.DefaultClient without transport options")
+	}
+}
+
+func TestNewClientTransportOptions(t *testing.T) {
+	const (
+		keepAlive   = 15 * time.Second
+		idleTimeout = 45 * time.Second
+	)
+
+	client := NewClient(WithTCPKeepAlive(keepAlive), WithIdleConnTimeout(idleTimeout))
+
+	transport, ok := client.httpClient().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport got %T, want *http.Transport", client.httpClient().Transport)
+	}
+	if transport.IdleConnTimeout != idleTimeout {
+		t.Errorf("IdleConnTimeout got %v, want %v", transport.IdleConnTimeout, idleTimeout)
+	}
+	if transport.DialContext == nil {
+		t.Error("DialContext must be set")
+	}
+
+	var opts clientOptions
+	WithTCPKeepAlive(keepAlive).applyClient(&opts)
+	if dialer := opts.dialer(); dialer.KeepAlive != keepAlive {
+		t.Errorf("dialer KeepAlive got %v, want %v", dialer.KeepAlive, keepAlive)
+	}
+}
+
+func TestNewClientMaxConnsPerHost(t *testing.T) {
+	const (
+		maxConns = 2
+		calls    = 20
+	)
+
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		time.Sleep(10 * time.Millisecond)
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMaxConnsPerHost(maxConns))
+
+	transport := client.httpClient().Transport.(*http.Transport)
+	if transport.MaxConnsPerHost != maxConns {
+		t.Fatalf("MaxConnsPerHost got %d, want %d", transport.MaxConnsPerHost, maxConns)
+	}
+	var dials int32
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return dial(ctx, network, addr)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var result string
+			if err := client.Call(context.Background(), server.URL, "conn", nil, &result); err != nil {
+				t.Errorf("Client.Call() failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if dials := atomic.LoadInt32(&dials); dials > maxConns {
+		t.Errorf("transport dialed %d connections, want at most %d", dials, maxConns)
+	}
+}
+
+func TestClientCallInvalidRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	err := client.Call(context.Background(), server.URL, "test", nil, nil)
+
+	var rerr *InvalidRedirectError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("Client.Call() error got %v, want *InvalidRedirectError", err)
+	}
+	if rerr.StatusCode != http.StatusFound {
+		t.Errorf("InvalidRedirectError.StatusCode got %d, want %d", rerr.StatusCode, http.StatusFound)
+	}
+}
+
+func TestClientCallWithCacheControl(t *testing.T) {
+	var cacheControl atomic.Value
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cacheControl.Store(r.Header.Get("Cache-Control"))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		method string
+		want   string
+	}{
+		"configured": {
+			method: "user.get",
+			want:   "max-age=60",
+		},
+		"not configured": {
+			method: "user.update",
+			want:   "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			err := client.Call(context.Background(), server.URL, tt.method, nil, &result,
+				WithCacheControl("user.get", time.Minute),
+				WithCacheControl("user.list", 10*time.Second))
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+
+			if got := cacheControl.Load().(string); got != tt.want {
+				t.Errorf("Cache-Control got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCallWithErrorCodeMapper(t *testing.T) {
+	const customCode ErrorCode = 1001
+
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return nil, &ResponseError{Code: customCode, Message: "custom error"}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	mapper := WithErrorCodeMapper(func(code ErrorCode) ErrorCode {
+		if code == customCode {
+			return InternalError
+		}
+		return code
+	})
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "test", nil, &result, mapper)
+
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Client.Call() error got %v, want *ResponseError", err)
+	}
+	if rpcErr.Code != InternalError {
+		t.Errorf("ResponseError.Code got %d, want %d", rpcErr.Code, InternalError)
+	}
+	if rpcErr.OriginalCode != customCode {
+		t.Errorf("ResponseError.OriginalCode got %d, want %d", rpcErr.OriginalCode, customCode)
+	}
+
+	res, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{{Method: "test"}}, mapper)
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	if rpcErr := res.Responses[0].Error; rpcErr == nil || rpcErr.Code != InternalError || rpcErr.OriginalCode != customCode {
+		t.Errorf("error of batch response got %+v, want InternalError mapped from %d", rpcErr, customCode)
+	}
+}
+
+func TestClientCallWithAcceptStatus(t *testing.T) {
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "queued", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("empty") != "" {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(rec.Body.Bytes())
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		client  *Client
+		url     string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		"rejected": {
+			client:  &Client{},
+			url:     server.URL,
+			wantErr: true,
+		},
+		"accepted by call": {
+			client: &Client{},
+			url:    server.URL,
+			opts:   []Option{WithAcceptStatus(http.StatusAccepted)},
+			want:   "queued",
+		},
+		"accepted by client": {
+			client: &Client{AcceptStatus: []int{http.StatusAccepted}},
+			url:    server.URL,
+			want:   "queued",
+		},
+		"overridden by call": {
+			client:  &Client{AcceptStatus: []int{http.StatusAccepted}},
+			url:     server.URL,
+			opts:    []Option{WithAcceptStatus()},
+			wantErr: true,
+		},
+		"empty body": {
+			client: &Client{},
+			url:    server.URL + "?empty=1",
+			opts:   []Option{WithAcceptStatus(http.StatusAccepted)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			err := tt.client.Call(context.Background(), tt.url, "enqueue", nil, &result, tt.opts...)
+			if tt.wantErr {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusAccepted {
+					t.Errorf("Client.Call() error got %v, want 202 StatusError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("Client.Call() got %q, want %q", result, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package jsonrpc
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is a source of the current time and timers used by the Client.
+// It can be replaced by WithClock, e.g. to control the time in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after d elapses,
+	// and returns a Timer that can cancel the call.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by Clock.AfterFunc.
+type Timer interface {
+	// Stop prevents the timer from firing.
+	// It returns false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// clockOrDefault returns the clock of the client, or the system clock if it is not set.
+func (client *Client) clockOrDefault() Clock {
+	if client.clock == nil {
+		return systemClock{}
+	}
+	return client.clock
+}
+
+// WithClock returns a ClientOption that sets the clock used by the Client.
+// The system clock is used by default.
+func WithClock(clock Clock) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.Clock = clock
+	})
+}
+
+// sleep waits for d by the clock of the client, or returns ctx.Err() if ctx is done before d elapses.
+func (client *Client) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	done := make(chan struct{})
+	timer := client.clockOrDefault().AfterFunc(d, func() {
+		close(done)
+	})
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package jsonrpc
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock which advances only by Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance advances the clock by d, and calls the functions of the timers expired synchronously.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+
+	var expired, pending []*fakeTimer
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+		} else {
+			expired = append(expired, timer)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].at.Before(expired[j].at)
+	})
+	for _, timer := range expired {
+		timer.f()
+	}
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestNewClientWithClock(t *testing.T) {
+	if _, ok := (&Client{}).clockOrDefault().(systemClock); !ok {
+		t.Error("Client.clockOrDefault() must be the system clock by default")
+	}
+
+	clock := newFakeClock()
+	client := NewClient(WithClock(clock))
+	if client.clockOrDefault() != clock {
+		t.Error("Client.clockOrDefault() must be the clock set by WithClock")
+	}
+}
@@ -0,0 +1,93 @@
+package jsonrpc
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Config is a configuration to build a Client by NewFromConfig,
+// e.g. loaded from a configuration file or environment variables.
+type Config struct {
+	// Endpoint is the url of the server, used by calls with an empty url.
+	Endpoint string
+
+	// Username and Password are credentials for the basic authentication.
+	// The basic authentication is not used if Username is empty.
+	Username string
+	Password string
+
+	// BearerToken is a token for the bearer authentication.
+	// It cannot be used with the basic authentication.
+	BearerToken string
+
+	// Timeout is the time limit of each HTTP request. No timeout if it is 0.
+	Timeout time.Duration
+
+	// Header is the header sent with every request.
+	Header http.Header
+}
+
+func (cfg *Config) validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint is empty")
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid endpoint scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("endpoint has no host")
+	}
+
+	if cfg.Username == "" && cfg.Password != "" {
+		return errors.New("password is set without username")
+	}
+	if cfg.Username != "" && cfg.BearerToken != "" {
+		return errors.New("both basic and bearer authentication are set")
+	}
+
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("negative timeout: %v", cfg.Timeout)
+	}
+
+	return nil
+}
+
+// NewFromConfig validates cfg and returns a new Client built from it.
+// Calls of the Client can be sent to the endpoint of cfg with an empty url.
+func NewFromConfig(cfg Config) (*Client, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	header := cfg.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	switch {
+	case cfg.Username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(cfg.Username + ":" + cfg.Password))
+		header.Set("Authorization", "Basic "+credentials)
+	case cfg.BearerToken != "":
+		header.Set("Authorization", "Bearer "+cfg.BearerToken)
+	}
+
+	client := &Client{
+		Endpoint: cfg.Endpoint,
+		Header:   header,
+	}
+	if cfg.Timeout > 0 {
+		client.HTTPClient = &http.Client{
+			Timeout: cfg.Timeout,
+		}
+	}
+
+	return client, nil
+}
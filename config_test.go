@@ -0,0 +1,111 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	var authorization, custom atomic.Value
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		custom.Store(r.Header.Get("X-Custom"))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		cfg  Config
+		want string
+	}{
+		"basic": {
+			cfg: Config{
+				Endpoint: server.URL,
+				Username: "user",
+				Password: "pass",
+			},
+			want: "Basic dXNlcjpwYXNz",
+		},
+		"bearer": {
+			cfg: Config{
+				Endpoint:    server.URL,
+				BearerToken: "token",
+				Timeout:     time.Second,
+			},
+			want: "Bearer token",
+		},
+		"no auth": {
+			cfg: Config{
+				Endpoint: server.URL,
+			},
+			want: "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.cfg.Header = http.Header{"X-Custom": {"custom"}}
+
+			client, err := NewFromConfig(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewFromConfig() failed: %v", err)
+			}
+			if tt.cfg.Timeout > 0 && client.httpClient().Timeout != tt.cfg.Timeout {
+				t.Errorf("Timeout got %v, want %v", client.httpClient().Timeout, tt.cfg.Timeout)
+			}
+
+			var result string
+			if err := client.Call(context.Background(), "", "test", nil, &result); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+
+			if got := authorization.Load().(string); got != tt.want {
+				t.Errorf("Authorization got %q, want %q", got, tt.want)
+			}
+			if got := custom.Load().(string); got != "custom" {
+				t.Errorf("X-Custom got %q, want %q", got, "custom")
+			}
+		})
+	}
+}
+
+func TestNewFromConfigInvalid(t *testing.T) {
+	tests := map[string]Config{
+		"empty endpoint": {},
+		"invalid scheme": {
+			Endpoint: "ftp://example.com",
+		},
+		"no host": {
+			Endpoint: "http://",
+		},
+		"both basic and bearer": {
+			Endpoint:    "http://example.com",
+			Username:    "user",
+			Password:    "pass",
+			BearerToken: "token",
+		},
+		"password without username": {
+			Endpoint: "http://example.com",
+			Password: "pass",
+		},
+		"negative timeout": {
+			Endpoint: "http://example.com",
+			Timeout:  -time.Second,
+		},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewFromConfig(cfg); err == nil {
+				t.Error("NewFromConfig() must fail")
+			}
+		})
+	}
+}
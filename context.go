@@ -0,0 +1,53 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ContextError represents a call failed because its context is canceled or its deadline is exceeded.
+// Err wraps context.Canceled or context.DeadlineExceeded, so errors.Is works for them.
+type ContextError struct {
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ContextError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON implements json.Marshaler.
+// The underlying error is marshaled as its message.
+func (e *ContextError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Error   string `json:"error"`
+		Timeout bool   `json:"timeout"`
+	}{
+		Error:   errorMessage(e.Err),
+		Timeout: e.Timeout(),
+	})
+}
+
+// Timeout reports whether the call failed because the deadline of its context is exceeded.
+func (e *ContextError) Timeout() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// contextError returns err as a *ContextError if err is caused by ctx being done.
+func contextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	var cerr *ContextError
+	if errors.As(err, &cerr) || !errors.Is(err, ctx.Err()) {
+		return err
+	}
+
+	return &ContextError{Err: err}
+}
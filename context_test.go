@@ -0,0 +1,84 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientCallContextError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		newContext func() (context.Context, context.CancelFunc)
+		target     error
+		timeout    bool
+	}{
+		"deadline": {
+			newContext: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			target:  context.DeadlineExceeded,
+			timeout: true,
+		},
+		"canceled": {
+			newContext: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			target:  context.Canceled,
+			timeout: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := tt.newContext()
+			defer cancel()
+
+			err := client.Call(ctx, server.URL, "test", nil, nil)
+
+			var cerr *ContextError
+			if !errors.As(err, &cerr) {
+				t.Fatalf("Client.Call() error got %v, want *ContextError", err)
+			}
+			if !errors.Is(err, tt.target) {
+				t.Errorf("Client.Call() error got %v, want %v", err, tt.target)
+			}
+			if cerr.Timeout() != tt.timeout {
+				t.Errorf("ContextError.Timeout() got %v, want %v", cerr.Timeout(), tt.timeout)
+			}
+		})
+	}
+}
+
+func TestClientCallServerErrorIsNotContextError(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := client.Call(ctx, server.URL, "test", nil, nil)
+
+	var cerr *ContextError
+	if errors.As(err, &cerr) {
+		t.Errorf("Client.Call() error got %v, want not *ContextError", err)
+	}
+}
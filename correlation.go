@@ -0,0 +1,83 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultCorrelationHeader is the header name used to send the correlation id
+// if Client.CorrelationHeader is empty.
+const DefaultCorrelationHeader = "X-Correlation-ID"
+
+// WithCorrelationID returns an Option that sends id in the correlation header of the Client,
+// so that the server can echo it in the data of the error.
+//
+// The data echoing the id is either the id itself as a string,
+// or an object with the id in the "correlationId" member.
+// If the echoed id is not matched to id, the call returns a CorrelationMismatchError
+// wrapping the ResponseError.
+func WithCorrelationID(id string) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.CorrelationID = id
+	})
+}
+
+// CorrelationMismatchError is returned when the server echoes a correlation id
+// which is not matched to the one sent by WithCorrelationID.
+type CorrelationMismatchError struct {
+	// ID is the correlation id sent to the server.
+	ID string `json:"id"`
+	// Echoed is the correlation id echoed in the data of the error.
+	Echoed string `json:"echoed"`
+	// Err is the error responded by the server.
+	Err *ResponseError `json:"error"`
+}
+
+func (err *CorrelationMismatchError) Error() string {
+	return fmt.Sprintf("correlation id is not matched: got %q, want %q: %v", err.Echoed, err.ID, err.Err)
+}
+
+func (err *CorrelationMismatchError) Unwrap() error {
+	return err.Err
+}
+
+func (client *Client) correlationHeader() string {
+	if client.CorrelationHeader == "" {
+		return DefaultCorrelationHeader
+	}
+
+	return client.CorrelationHeader
+}
+
+// checkCorrelationID returns a CorrelationMismatchError if err is a ResponseError
+// echoing a correlation id other than the one of opts, otherwise returns err.
+func (opts *callOptions) checkCorrelationID(err error) error {
+	var rpcErr *ResponseError
+	if opts.CorrelationID == "" || !errors.As(err, &rpcErr) {
+		return err
+	}
+
+	echoed, ok := echoedCorrelationID(rpcErr.Data)
+	if !ok || echoed == opts.CorrelationID {
+		return err
+	}
+
+	return &CorrelationMismatchError{
+		ID:     opts.CorrelationID,
+		Echoed: echoed,
+		Err:    rpcErr,
+	}
+}
+
+// echoedCorrelationID returns the correlation id echoed in the data of an error.
+func echoedCorrelationID(data interface{}) (string, bool) {
+	switch data := data.(type) {
+	case string:
+		return data, true
+	case map[string]interface{}:
+		id, ok := data["correlationId"].(string)
+		return id, ok
+	}
+
+	return "", false
+}
@@ -0,0 +1,72 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCallWithCorrelationID(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		echo     func(id string) interface{}
+		mismatch bool
+	}{
+		"string": {
+			echo: func(id string) interface{} { return id },
+		},
+		"object": {
+			header: "X-Request-ID",
+			echo:   func(id string) interface{} { return map[string]string{"correlationId": id} },
+		},
+		"mismatch": {
+			echo:     func(id string) interface{} { return map[string]string{"correlationId": "other"} },
+			mismatch: true,
+		},
+		"absent": {
+			echo: func(id string) interface{} { return map[string]int{"retry": 1} },
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := tt.header
+			if header == "" {
+				header = DefaultCorrelationHeader
+			}
+
+			var id string
+			handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+				return nil, &ResponseError{Code: -32000, Message: "failed", Data: tt.echo(id)}
+			})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id = r.Header.Get(header)
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client := &Client{CorrelationHeader: tt.header}
+
+			var result string
+			err := client.Call(context.Background(), server.URL, "test", nil, &result, WithCorrelationID("corr-1"))
+			if id != "corr-1" {
+				t.Errorf("%s header got %q, want %q", header, id, "corr-1")
+			}
+
+			var rpcErr *ResponseError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != -32000 {
+				t.Fatalf("Client.Call() error got %v, want ResponseError", err)
+			}
+
+			var mismatchErr *CorrelationMismatchError
+			if got := errors.As(err, &mismatchErr); got != tt.mismatch {
+				t.Fatalf("Client.Call() error got %v, want CorrelationMismatchError %v", err, tt.mismatch)
+			}
+			if tt.mismatch && (mismatchErr.ID != "corr-1" || mismatchErr.Echoed != "other") {
+				t.Errorf("CorrelationMismatchError got %q, %q, want %q, %q", mismatchErr.ID, mismatchErr.Echoed, "corr-1", "other")
+			}
+		})
+	}
+}
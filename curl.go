@@ -0,0 +1,102 @@
+package jsonrpc
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// WithCurlDump returns an Option that writes each HTTP request of the call to w
+// as an equivalent curl command line, e.g. to reproduce the call for debugging.
+// The values of the headers named in redact, e.g. "Authorization", are replaced with "REDACTED".
+//
+// A request is written before it is sent, so a retried call writes a command for each attempt.
+// A request body streamed by a ParamsEncoder is written as "--data-binary @-".
+// Errors writing to w are ignored.
+func WithCurlDump(w io.Writer, redact ...string) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.CurlDump = w
+		opts.CurlRedact = append(opts.CurlRedact, redact...)
+	})
+}
+
+// dumpCurl writes req to the writer of WithCurlDump as a curl command line.
+func (opts *callOptions) dumpCurl(req *http.Request) {
+	if opts.CurlDump == nil {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("curl -X ")
+	b.WriteString(req.Method)
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		redacted := opts.redacted(key)
+		for _, value := range req.Header[key] {
+			if redacted {
+				value = "REDACTED"
+			}
+			b.WriteString(" -H ")
+			b.WriteString(shellQuote(key + ": " + value))
+		}
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		b.WriteString(" --data-binary ")
+		if body, ok := requestBodyBytes(req); ok {
+			b.WriteString(shellQuote(string(body)))
+		} else {
+			b.WriteString("@-")
+		}
+	}
+
+	b.WriteString(" ")
+	b.WriteString(shellQuote(req.URL.String()))
+	b.WriteString("\n")
+
+	io.WriteString(opts.CurlDump, b.String())
+}
+
+func (opts *callOptions) redacted(key string) bool {
+	for _, name := range opts.CurlRedact {
+		if http.CanonicalHeaderKey(name) == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestBodyBytes returns a copy of the body of req without consuming it,
+// or false if the body cannot be copied.
+func requestBodyBytes(req *http.Request) ([]byte, bool) {
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, false
+	}
+
+	return b, true
+}
+
+// shellQuote quotes s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,46 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCallWithCurlDump(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+
+	var buf strings.Builder
+	var result string
+	err := client.Call(context.Background(), server.URL, "dump", map[string]string{"name": "it's"}, &result,
+		WithHeader(header), WithCurlDump(&buf, "authorization"))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"curl -X POST ",
+		"'" + server.URL + "'",
+		"-H 'Content-Type: application/json; charset=utf-8'",
+		"-H 'Authorization: REDACTED'",
+		`"method":"dump"`,
+		`"params":{"name":"it'\''s"}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("curl dump got %q, want to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "secret") {
+		t.Errorf("curl dump got %q, want the Authorization redacted", got)
+	}
+}
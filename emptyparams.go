@@ -0,0 +1,58 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// EmptyParamsMode is how the params marshaled to an empty object are sent.
+type EmptyParamsMode int
+
+const (
+	// EmptyParamsOmit omits the params member from the request.
+	EmptyParamsOmit EmptyParamsMode = iota + 1
+	// EmptyParamsObject sends the params as {}.
+	EmptyParamsObject
+	// EmptyParamsNull sends the params as null.
+	EmptyParamsNull
+)
+
+// WithEmptyParamsAs returns an Option that sends the params marshaled to an empty object in the mode,
+// e.g. an empty map or struct, for the servers requiring either "params": {} or no params.
+// nil params are omitted regardless of the mode,
+// and it is not applied to the params streamed by a ParamsEncoder.
+func WithEmptyParamsAs(mode EmptyParamsMode) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.EmptyParams = mode
+	})
+}
+
+// apply returns the params raw to send in the mode.
+func (mode EmptyParamsMode) apply(raw json.RawMessage) interface{} {
+	if mode == 0 || !isEmptyObject(raw) {
+		return raw
+	}
+
+	switch mode {
+	case EmptyParamsOmit:
+		return nil
+	case EmptyParamsNull:
+		return json.RawMessage("null")
+	}
+
+	return json.RawMessage("{}")
+}
+
+// isEmptyObject reports whether raw is a JSON object without members.
+func isEmptyObject(raw json.RawMessage) bool {
+	if b := bytes.TrimSpace(raw); len(b) == 0 || b[0] != '{' {
+		return false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return false
+	}
+
+	return len(fields) == 0
+}
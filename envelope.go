@@ -0,0 +1,99 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// EnvelopeError is returned when the JSON-RPC response cannot be extracted from the envelope of WithResponseEnvelopePath.
+type EnvelopeError struct {
+	// Path is the path of the JSON-RPC response in the envelope.
+	Path string `json:"path"`
+	// Message describes why the response cannot be extracted.
+	Message string `json:"message"`
+}
+
+func (err *EnvelopeError) Error() string {
+	return fmt.Sprintf("failed to extract response from envelope at %q: %s", err.Path, err.Message)
+}
+
+// WithResponseEnvelopePath returns an Option that extracts the JSON-RPC response
+// wrapped in an envelope by a gateway, e.g. {"status":"ok","payload":<response>}, before decoding it.
+// path is the keys of the objects to the response separated by dots, e.g. "payload" or "data.rpc".
+// The response is extracted after verified by WithResponseVerifier, and before rewritten by WithResponseRewriter.
+//
+// The response body is read entirely up to MaxRewriteBytes, so results are not streamed with this option.
+// The call fails with an EnvelopeError if the path is missing, or the value at the path is not a JSON-RPC response.
+func WithResponseEnvelopePath(path string) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ResponseEnvelope = path
+	})
+}
+
+// unwrapResponseEnvelope reads the body of res, and replaces it with the JSON-RPC response at the ResponseEnvelope path.
+func (opts *callOptions) unwrapResponseEnvelope(res *http.Response) error {
+	if opts.ResponseEnvelope == "" {
+		return nil
+	}
+
+	b, err := readRewriteBody(res)
+	if err != nil {
+		return err
+	}
+
+	inner, err := extractEnvelope(b, opts.ResponseEnvelope)
+	if err != nil {
+		return err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(inner))
+
+	return nil
+}
+
+// extractEnvelope returns the JSON-RPC response at path in the envelope b.
+func extractEnvelope(b []byte, path string) (json.RawMessage, error) {
+	raw := json.RawMessage(b)
+	for _, key := range strings.Split(path, ".") {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+			return nil, &EnvelopeError{Path: path, Message: "envelope is not an object"}
+		}
+		v, ok := obj[key]
+		if !ok {
+			return nil, &EnvelopeError{Path: path, Message: fmt.Sprintf("missing key %q", key)}
+		}
+		raw = v
+	}
+
+	if !isResponse(raw) {
+		return nil, &EnvelopeError{Path: path, Message: "value is not a JSON-RPC response"}
+	}
+
+	return raw, nil
+}
+
+// isResponse reports whether raw is a JSON-RPC response object or a batch of them.
+func isResponse(raw json.RawMessage) bool {
+	var batch []json.RawMessage
+	if err := json.Unmarshal(raw, &batch); err == nil {
+		for _, r := range batch {
+			if !isResponse(r) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		return false
+	}
+	_, hasResult := obj["result"]
+	_, hasError := obj["error"]
+
+	return hasResult || hasError
+}
@@ -0,0 +1,63 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCallWithResponseEnvelopePath(t *testing.T) {
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "wrapped", nil
+	})
+	// the gateway wraps the response in the envelope.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		switch r.URL.Path {
+		case "/invalid":
+			fmt.Fprint(w, `{"status":"ok","payload":{"message":"not a response"}}`)
+		default:
+			fmt.Fprintf(w, `{"status":"ok","payload":%s}`, rec.Body.Bytes())
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		path    string
+		url     string
+		wantErr bool
+	}{
+		"wrapped":      {path: "payload", url: server.URL},
+		"missing path": {path: "data.rpc", url: server.URL, wantErr: true},
+		"invalid":      {path: "payload", url: server.URL + "/invalid", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			err := client.Call(context.Background(), tt.url, "echo", nil, &result, WithResponseEnvelopePath(tt.path))
+			if tt.wantErr {
+				var envErr *EnvelopeError
+				if !errors.As(err, &envErr) {
+					t.Fatalf("Client.Call() error got %v, want *EnvelopeError", err)
+				}
+				if envErr.Path != tt.path {
+					t.Errorf("EnvelopeError.Path got %q, want %q", envErr.Path, tt.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if result != "wrapped" {
+				t.Errorf("Client.Call() got %q, want %q", result, "wrapped")
+			}
+		})
+	}
+}
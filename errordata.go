@@ -0,0 +1,70 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// RegisterErrorDataType registers the type of proto as the type of the data of the errors with the code.
+// The Data of a ResponseError with the code is decoded into a newly allocated value of the type,
+// and it is a pointer to the value.
+// If proto is a pointer, the type it points to is registered.
+//
+// The code is matched after it is mapped by WithErrorCodeMapper.
+// If the data cannot be decoded into the type, Data is left as is.
+func (client *Client) RegisterErrorDataType(code ErrorCode, proto interface{}) {
+	t := reflect.TypeOf(proto)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	client.errorDataTypesMu.Lock()
+	defer client.errorDataTypesMu.Unlock()
+
+	if client.errorDataTypes == nil {
+		client.errorDataTypes = make(map[ErrorCode]reflect.Type)
+	}
+	client.errorDataTypes[code] = t
+}
+
+func (client *Client) errorDataType(code ErrorCode) (reflect.Type, bool) {
+	client.errorDataTypesMu.RLock()
+	defer client.errorDataTypesMu.RUnlock()
+
+	t, ok := client.errorDataTypes[code]
+	return t, ok
+}
+
+// decodeErrorData decodes the Data of err into the type registered for its code.
+func (client *Client) decodeErrorData(err *ResponseError) {
+	if err == nil || err.Data == nil {
+		return
+	}
+
+	t, ok := client.errorDataType(err.Code)
+	if !ok {
+		return
+	}
+
+	b, e := json.Marshal(err.Data)
+	if e != nil {
+		return
+	}
+
+	data := reflect.New(t).Interface()
+	if e := json.Unmarshal(b, data); e != nil {
+		return
+	}
+	err.Data = data
+}
+
+// WithErrorDataType returns a ClientOption that registers the type of proto
+// as the type of the data of the errors with the code, same as RegisterErrorDataType.
+func WithErrorDataType(code ErrorCode, proto interface{}) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		if opts.ErrorDataTypes == nil {
+			opts.ErrorDataTypes = make(map[ErrorCode]interface{})
+		}
+		opts.ErrorDataTypes[code] = proto
+	})
+}
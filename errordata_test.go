@@ -0,0 +1,61 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testNotFoundData struct {
+	Resource string `json:"resource"`
+}
+
+type testInvalidData struct {
+	Fields []string `json:"fields"`
+}
+
+func TestClientCallErrorDataType(t *testing.T) {
+	const (
+		notFound ErrorCode = -32001
+		invalid  ErrorCode = -32002
+		unknown  ErrorCode = -32003
+	)
+
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		switch req.Method {
+		case "notFound":
+			return nil, &ResponseError{Code: notFound, Message: "not found", Data: map[string]string{"resource": "user"}}
+		case "invalid":
+			return nil, &ResponseError{Code: invalid, Message: "invalid", Data: map[string][]string{"fields": {"name", "age"}}}
+		}
+		return nil, &ResponseError{Code: unknown, Message: "unknown", Data: map[string]string{"reason": "unknown"}}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithErrorDataType(notFound, testNotFoundData{}))
+	client.RegisterErrorDataType(invalid, &testInvalidData{})
+
+	tests := map[string]struct {
+		want interface{}
+	}{
+		"notFound": {want: &testNotFoundData{Resource: "user"}},
+		"invalid":  {want: &testInvalidData{Fields: []string{"name", "age"}}},
+		"unknown":  {want: map[string]interface{}{"reason": "unknown"}},
+	}
+
+	for method, tt := range tests {
+		t.Run(method, func(t *testing.T) {
+			var result string
+			err := client.Call(context.Background(), server.URL, method, nil, &result)
+			var rpcErr *ResponseError
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("Client.Call() error got %v, want *ResponseError", err)
+			}
+			if !reflect.DeepEqual(rpcErr.Data, tt.want) {
+				t.Errorf("ResponseError.Data got %#v, want %#v", rpcErr.Data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestErrorMarshalJSON(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want string
+	}{
+		"ResponseError": {
+			err:  &ResponseError{Code: InternalError, Message: "internal error", Data: map[string]int{"retry": 1}},
+			want: `{"code":-32603,"message":"internal error","data":{"retry":1}}`,
+		},
+		"ResponseError with codes": {
+			err:  &ResponseError{Code: 0, Message: "not found", StringCode: "NOT_FOUND", OriginalCode: 404},
+			want: `{"code":0,"message":"not found","stringCode":"NOT_FOUND","originalCode":404}`,
+		},
+		"StatusError": {
+			err:  &StatusError{StatusCode: 503, Status: "503 Service Unavailable"},
+			want: `{"statusCode":503,"status":"503 Service Unavailable"}`,
+		},
+		"InvalidRedirectError": {
+			err:  &InvalidRedirectError{StatusCode: 302, Status: "302 Found"},
+			want: `{"statusCode":302,"status":"302 Found"}`,
+		},
+		"UnexpectedResultError": {
+			err:  &UnexpectedResultError{Result: json.RawMessage(`[1]`)},
+			want: `{"result":[1]}`,
+		},
+		"BudgetExceededError": {
+			err:  &BudgetExceededError{Budget: 100, Transferred: 120},
+			want: `{"budget":100,"transferred":120}`,
+		},
+		"RequestTooLargeError": {
+			err:  &RequestTooLargeError{Method: "upload", Size: 20, MaxBytes: 10},
+			want: `{"method":"upload","size":20,"maxBytes":10}`,
+		},
+		"ContextError": {
+			err:  &ContextError{Err: context.DeadlineExceeded},
+			want: `{"error":"context deadline exceeded","timeout":true}`,
+		},
+		"CorrelationMismatchError": {
+			err:  &CorrelationMismatchError{ID: "a", Echoed: "b", Err: &ResponseError{Code: InternalError, Message: "internal error"}},
+			want: `{"id":"a","echoed":"b","error":{"code":-32603,"message":"internal error"}}`,
+		},
+		"CertPinError": {
+			err:  &CertPinError{ServerName: "example.com", SHA256: "abcd"},
+			want: `{"serverName":"example.com","sha256":"abcd"}`,
+		},
+		"ProtocolError": {
+			err:  &ProtocolError{Kind: VersionMismatch, Message: "version mismatch", Version: "1.0"},
+			want: `{"kind":"VersionMismatch","message":"version mismatch","version":"1.0"}`,
+		},
+		"IncompleteResultError": {
+			err:  &IncompleteResultError{Items: 3, Err: io.ErrUnexpectedEOF},
+			want: `{"items":3,"error":"unexpected EOF"}`,
+		},
+		"StreamInterruptedError": {
+			err:  &StreamInterruptedError{Token: "t3", Err: errors.New("broken")},
+			want: `{"token":"t3","error":"broken"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := json.Marshal(tt.err)
+			if err != nil {
+				t.Fatalf("json.Marshal() failed: %v", err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("json.Marshal() got %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Failover calls methods on multiple endpoints of the same service,
+// failing over to the next endpoint when an endpoint fails.
+// A failed endpoint is marked unhealthy and skipped until the cooldown elapses.
+// Each endpoint has its own connection pool, so that the connections to a failing endpoint
+// do not occupy the pool of the others.
+// It is safe for concurrent use.
+type Failover struct {
+	client    *Client
+	endpoints []*endpointHealth
+	cooldown  time.Duration
+
+	mu sync.Mutex
+}
+
+// endpointHealth is the health state of an endpoint.
+type endpointHealth struct {
+	url        string
+	httpClient *http.Client
+	failed     bool
+	failedAt   time.Time
+}
+
+// NewFailover returns a new Failover which calls the methods on the urls in the order of preference.
+// A failed endpoint is skipped for the cooldown, measured by the clock of the Client, see WithClock.
+//
+// The transport of the Client is cloned for each endpoint to have its own connection pool.
+// If the transport is not *http.Transport, it cannot be cloned and is shared by the endpoints.
+// The calls forcing the HTTP version by WithForceHTTP1 or WithForceHTTP2 use the transports of the Client.
+func (client *Client) NewFailover(urls []string, cooldown time.Duration) *Failover {
+	endpoints := make([]*endpointHealth, len(urls))
+	for i, url := range urls {
+		endpoints[i] = &endpointHealth{
+			url:        url,
+			httpClient: client.endpointHTTPClient(),
+		}
+	}
+
+	return &Failover{
+		client:    client,
+		endpoints: endpoints,
+		cooldown:  cooldown,
+	}
+}
+
+// Call calls the method on the first healthy endpoint like Client.Call,
+// and fails over to the next healthy endpoint if the endpoint fails.
+// An endpoint fails if the request cannot be sent or the server responds an unavailable status.
+// If all endpoints are unhealthy, they are tried in order anyway.
+//
+// A request may be sent to multiple endpoints, so Call should be used for idempotent methods.
+func (f *Failover) Call(ctx context.Context, method string, params interface{}, result interface{}, opts ...Option) error {
+	endpoints := f.candidates()
+	if len(endpoints) == 0 {
+		return errors.New("no endpoint")
+	}
+
+	var err error
+	for _, e := range endpoints {
+		err = f.client.Call(ctx, e.url, method, params, result, append(opts[:len(opts):len(opts)], withHTTPClient(e.httpClient))...)
+		if err == nil {
+			f.markHealthy(e)
+			return nil
+		}
+		if ctx.Err() != nil || !endpointFailed(err) {
+			return err
+		}
+
+		f.markFailed(e)
+	}
+
+	return err
+}
+
+// Healthy returns the urls of the healthy endpoints in the order of preference.
+func (f *Failover) Healthy() []string {
+	var urls []string
+	for _, e := range f.healthyEndpoints() {
+		urls = append(urls, e.url)
+	}
+	return urls
+}
+
+// CloseIdleConnections closes the idle connections in the connection pools of the endpoints.
+func (f *Failover) CloseIdleConnections() {
+	for _, e := range f.endpoints {
+		e.httpClient.CloseIdleConnections()
+	}
+}
+
+// healthyEndpoints returns the healthy endpoints in the order of preference.
+func (f *Failover) healthyEndpoints() []*endpointHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.client.clockOrDefault().Now()
+
+	var endpoints []*endpointHealth
+	for _, e := range f.endpoints {
+		if f.healthy(e, now) {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// candidates returns the healthy endpoints, or all endpoints if no endpoint is healthy.
+func (f *Failover) candidates() []*endpointHealth {
+	if endpoints := f.healthyEndpoints(); len(endpoints) > 0 {
+		return endpoints
+	}
+
+	return f.endpoints
+}
+
+// healthy reports whether the endpoint is healthy at now. f.mu must be held.
+func (f *Failover) healthy(e *endpointHealth, now time.Time) bool {
+	return !e.failed || now.Sub(e.failedAt) >= f.cooldown
+}
+
+func (f *Failover) markFailed(e *endpointHealth) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	e.failed = true
+	e.failedAt = f.client.clockOrDefault().Now()
+}
+
+func (f *Failover) markHealthy(e *endpointHealth) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	e.failed = false
+}
+
+// endpointHTTPClient returns an HTTP client of the Client with the transport cloned for an endpoint of Failover.
+func (client *Client) endpointHTTPClient() *http.Client {
+	base := client.httpClient()
+
+	rt := base.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return base
+	}
+
+	c := *base
+	c.Transport = transport.Clone()
+	return &c
+}
+
+// withHTTPClient returns an Option that sends the requests of the call by httpClient.
+func withHTTPClient(httpClient *http.Client) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.httpClient = httpClient
+	})
+}
+
+// endpointFailed reports whether err means that the endpoint itself failed,
+// rather than the call failed on a working endpoint.
+func endpointFailed(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+
+	return isTransportError(err)
+}
@@ -0,0 +1,125 @@
+package jsonrpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFailover(t *testing.T) {
+	var failingCalls int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&failingCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	var workingCalls int32
+	working := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		atomic.AddInt32(&workingCalls, 1)
+		return "ok", nil
+	}))
+	defer working.Close()
+
+	clock := newFakeClock()
+	client := NewClient(WithClock(clock))
+	failover := client.NewFailover([]string{failing.URL, working.URL}, time.Minute)
+
+	call := func() {
+		t.Helper()
+
+		var result string
+		if err := failover.Call(context.Background(), "test", nil, &result); err != nil {
+			t.Fatalf("Failover.Call() failed: %v", err)
+		}
+	}
+
+	call()
+	if got := atomic.LoadInt32(&failingCalls); got != 1 {
+		t.Errorf("failing endpoint got %d calls, want 1", got)
+	}
+	if got, want := failover.Healthy(), []string{working.URL}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Failover.Healthy() got %v, want %v", got, want)
+	}
+
+	call()
+	if got := atomic.LoadInt32(&failingCalls); got != 1 {
+		t.Errorf("failing endpoint got %d calls in the cooldown, want 1", got)
+	}
+
+	clock.Advance(time.Minute)
+	if got, want := failover.Healthy(), []string{failing.URL, working.URL}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Failover.Healthy() got %v after the cooldown, want %v", got, want)
+	}
+
+	call()
+	if got := atomic.LoadInt32(&failingCalls); got != 2 {
+		t.Errorf("failing endpoint got %d calls after the cooldown, want 2", got)
+	}
+	if got := atomic.LoadInt32(&workingCalls); got != 3 {
+		t.Errorf("working endpoint got %d calls, want 3", got)
+	}
+}
+
+func TestFailoverResponseError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		atomic.AddInt32(&calls, 1)
+		return nil, &ResponseError{Code: InvalidParams, Message: "invalid params"}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+	failover := client.NewFailover([]string{server.URL, server.URL}, time.Minute)
+
+	var result string
+	if err := failover.Call(context.Background(), "test", nil, &result); err == nil {
+		t.Fatal("Failover.Call() must fail")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("server got %d calls, want 1 without failover", got)
+	}
+	if got := len(failover.Healthy()); got != 2 {
+		t.Errorf("Failover.Healthy() got %d endpoints, want 2", got)
+	}
+}
+
+func TestFailoverConnectionPools(t *testing.T) {
+	var calls int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	var conns int32
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := &Client{}
+	failover := client.NewFailover([]string{server.URL, server.URL}, time.Minute)
+	defer failover.CloseIdleConnections()
+
+	var result string
+	if err := failover.Call(context.Background(), "test", nil, &result); err != nil {
+		t.Fatalf("Failover.Call() failed: %v", err)
+	}
+
+	// the second endpoint does not reuse the connection of the first one.
+	if got := atomic.LoadInt32(&conns); got != 2 {
+		t.Errorf("server got %d connections, want 2 by the pool of each endpoint", got)
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/kechako/go-jsonrpc
 
-go 1.15
+go 1.18
 
 require github.com/google/uuid v1.1.1
@@ -0,0 +1,53 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// gzipMagic is the magic bytes at the beginning of a gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// WithForceGzipResponse returns an Option that decompresses the response body by gzip
+// even if the server does not respond with Content-Encoding: gzip,
+// for the misconfigured servers compressing the body without the header.
+//
+// The body is sniffed rather than decompressed unconditionally:
+// it is decompressed only if it starts with the gzip magic bytes 0x1f 0x8b,
+// which a JSON text never starts with, so the uncompressed responses are still decoded as is.
+func WithForceGzipResponse() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ForceGzipResponse = true
+	})
+}
+
+// sniffGzipBody decompresses rc by gzip if it starts with the gzip magic bytes, otherwise reads rc as is.
+// The body is sniffed on the first read.
+type sniffGzipBody struct {
+	rc  io.ReadCloser
+	r   io.Reader
+	err error
+}
+
+func (b *sniffGzipBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		br := bufio.NewReader(b.rc)
+		magic, _ := br.Peek(len(gzipMagic))
+		if bytes.Equal(magic, gzipMagic) {
+			b.r, b.err = gzip.NewReader(br)
+		} else {
+			b.r = br
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	return b.r.Read(p)
+}
+
+func (b *sniffGzipBody) Close() error {
+	return b.rc.Close()
+}
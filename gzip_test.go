@@ -0,0 +1,64 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCallWithForceGzipResponse(t *testing.T) {
+	// the server gzips the body on /gzip without Content-Encoding.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		body, _ := json.Marshal(&testResponse{
+			JSONRPC: Version,
+			Result:  "decompressed",
+			ID:      req.ID,
+		})
+		if r.URL.Path == "/gzip" {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			zw.Write(body)
+			zw.Close()
+			body = buf.Bytes()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL+"/gzip", "gzip", nil, &result); err == nil {
+		t.Fatalf("Client.Call() without the option got %q, want error", result)
+	}
+
+	tests := map[string]struct {
+		path string
+	}{
+		"gzipped": {path: "/gzip"},
+		"plain":   {path: "/plain"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			if err := client.Call(context.Background(), server.URL+tt.path, "gzip", nil, &result, WithForceGzipResponse()); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if result != "decompressed" {
+				t.Errorf("Client.Call() got %q, want %q", result, "decompressed")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// WithHedging returns an Option that sends a hedged request if no response is received within delay,
+// up to maxHedges times at the interval of delay, to reduce the tail latency.
+// The first successful response of the requests is used and the others are cancelled,
+// so it is only for methods which are idempotent.
+// Each hedged request is sent with its own id, and the responses received after the first successful one are discarded.
+//
+// If all the requests fail, the error of the first failed one is returned, and retried by WithRetry if retryable.
+func WithHedging(delay time.Duration, maxHedges int) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.HedgeDelay = delay
+		opts.MaxHedges = maxHedges
+	})
+}
+
+// hedgedResult is a result of a hedged request, which keeps the raw result
+// to be decoded only if the request wins.
+type hedgedResult struct {
+	raw     json.RawMessage
+	scanned bool
+}
+
+func (result *hedgedResult) ScanRPC(data json.RawMessage) error {
+	result.raw = append(result.raw[:0], data...)
+	result.scanned = true
+	return nil
+}
+
+// hedgedResponse is the outcome of a hedged request.
+type hedgedResponse struct {
+	id       requestID
+	result   hedgedResult
+	scratch  callScratch
+	stats    CallStats
+	envelope Response
+	err      error
+}
+
+// hedge sends the request body with the id, and the hedged requests of the method with new ids
+// while no response is received, and stores the result of the first successful response in the result.
+// The scratch, the stats and the envelope are replaced with the ones of the response used, and the id of the response is returned.
+func (client *Client) hedge(ctx context.Context, url string, method string, params interface{}, id requestID, body []byte, result interface{}, scratch *callScratch, callOpts callOptions) (requestID, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make(chan *hedgedResponse, callOpts.MaxHedges+1)
+	send := func(id requestID, body []byte) {
+		r := &hedgedResponse{id: id}
+		opts := callOpts
+		if opts.stats != nil {
+			opts.stats = &r.stats
+		}
+		if opts.envelope != nil {
+			opts.envelope = &r.envelope
+		}
+		go func() {
+			r.err = r.scratch.detach(client.call(ctx, url, id, bytes.NewReader(body), &r.result, &r.scratch, opts))
+			responses <- r
+		}()
+	}
+
+	send(id, body)
+	inflight, hedges := 1, 0
+
+	timer := time.NewTimer(callOpts.HedgeDelay)
+	defer timer.Stop()
+
+	var failed *hedgedResponse
+	for inflight > 0 {
+		select {
+		case <-timer.C:
+			hedgeID, hedgeBody, err := requestBody(method, params, callOpts)
+			if err != nil {
+				// the ids may be exhausted, so the requests in flight are waited without hedging.
+				continue
+			}
+			send(hedgeID, hedgeBody)
+			inflight++
+			hedges++
+			if hedges < callOpts.MaxHedges {
+				timer.Reset(callOpts.HedgeDelay)
+			}
+		case r := <-responses:
+			inflight--
+			if r.err != nil {
+				if failed == nil {
+					failed = r
+				}
+				continue
+			}
+
+			r.use(scratch, callOpts)
+			if !r.result.scanned {
+				return r.id, nil
+			}
+			return r.id, decodeResult(r.result.raw, result, callOpts)
+		}
+	}
+
+	failed.use(scratch, callOpts)
+	return failed.id, failed.err
+}
+
+// use replaces the scratch, the stats and the envelope of the call with the ones of the response.
+func (r *hedgedResponse) use(scratch *callScratch, callOpts callOptions) {
+	*scratch = r.scratch
+	if callOpts.stats != nil {
+		*callOpts.stats = r.stats
+	}
+	if callOpts.envelope != nil {
+		*callOpts.envelope = r.envelope
+	}
+}
@@ -0,0 +1,64 @@
+package jsonrpc
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCallWithHedging(t *testing.T) {
+	var calls int32
+	var mu sync.Mutex
+	var ids []string
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		mu.Lock()
+		ids = append(ids, string(req.ID))
+		mu.Unlock()
+		return "fast", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// the first request is slow, and is cancelled when the hedged one wins.
+			// the body is read so that the cancellation is detected.
+			io.Copy(ioutil.Discard, r.Body)
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	start := time.Now()
+	stats, err := client.CallWithStats(context.Background(), server.URL, "read", nil, &result, WithHedging(20*time.Millisecond, 1))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if d := time.Since(start); d >= 5*time.Second {
+		t.Errorf("Client.Call() took %v, want the hedged request to win", d)
+	}
+
+	if result != "fast" {
+		t.Errorf("Client.Call() got %q, want %q", result, "fast")
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls, want 2", calls)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ids) != 1 || string(stats.IDRaw) != ids[0] {
+		t.Errorf("CallStats.IDRaw got %s, want the id of the hedged request %v", stats.IDRaw, ids)
+	}
+}
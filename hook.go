@@ -0,0 +1,43 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// CallInfo describes a completed call passed to a CallHook.
+type CallInfo struct {
+	// Method is the method name of the call, or empty for a batch call.
+	Method string
+	// BatchSize is the number of the requests of a batch call, or 0 for a call of a single method.
+	BatchSize int
+	// Duration is the time taken by the call, including retries.
+	Duration time.Duration
+	// Err is the error the call returned, or nil if the call succeeded.
+	Err error
+	// IDRaw is the id of the request exactly as it is sent on the wire, like CallStats.IDRaw.
+	IDRaw json.RawMessage
+}
+
+// CallHook is a function called after each call completes,
+// e.g. to record metrics of the calls.
+// ctx is the context of the call derived from the one given by the caller,
+// which has the effective deadline of the call applied by WithTimeout and WithLearnedTimeout.
+type CallHook func(ctx context.Context, info CallInfo)
+
+// WithCallHook returns an Option that calls hook after the call completes.
+// The hooks are called in the order they are given.
+// They are called by Call, Notify, CallStream, CallBatch, CallBatchStream and CallBatchPartial,
+// and the calls built on them, but not by CallWithRawResponse, which leaves the response to the caller.
+func WithCallHook(hook CallHook) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.Hooks = append(opts.Hooks, hook)
+	})
+}
+
+func (opts *callOptions) runHooks(ctx context.Context, info CallInfo) {
+	for _, hook := range opts.Hooks {
+		hook(ctx, info)
+	}
+}
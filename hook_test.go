@@ -0,0 +1,157 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientCallWithCallHook(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "fail" {
+			return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+		}
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var infos []CallInfo
+	hook := WithCallHook(func(ctx context.Context, info CallInfo) {
+		infos = append(infos, info)
+	})
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "test", nil, &result, hook); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	err := client.Call(context.Background(), server.URL, "fail", nil, &result, hook)
+
+	if len(infos) != 2 {
+		t.Fatalf("hook is called %d times, want 2", len(infos))
+	}
+	if infos[0].Method != "test" || infos[0].Err != nil {
+		t.Errorf("first CallInfo got %+v, want method test without error", infos[0])
+	}
+	if infos[1].Method != "fail" || !errors.Is(infos[1].Err, err) {
+		t.Errorf("second CallInfo got %+v, want method fail with error %v", infos[1], err)
+	}
+	if infos[0].Duration <= 0 {
+		t.Errorf("CallInfo.Duration got %v, want positive", infos[0].Duration)
+	}
+}
+
+func TestClientCallHookDeadline(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	callerDeadline, _ := ctx.Deadline()
+
+	var hookDeadline time.Time
+	var hasDeadline bool
+	hook := WithCallHook(func(ctx context.Context, info CallInfo) {
+		hookDeadline, hasDeadline = ctx.Deadline()
+	})
+
+	var result string
+	if err := client.Call(ctx, server.URL, "test", nil, &result, hook, WithTimeout(time.Minute)); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	end := time.Now()
+
+	if !hasDeadline {
+		t.Fatal("hook got a context without deadline")
+	}
+	if !hookDeadline.Before(callerDeadline) || hookDeadline.After(end.Add(time.Minute)) {
+		t.Errorf("hook got deadline %v, want the reduced deadline within a minute from %v", hookDeadline, end)
+	}
+}
+
+func TestClientCallHookCallPaths(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return []int{1}, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+	reqs := []BatchRequest{{Method: "a"}, {Method: "b"}}
+
+	tests := map[string]struct {
+		call      func(hook Option) error
+		method    string
+		batchSize int
+		idRaw     bool
+	}{
+		"Notify": {
+			call: func(hook Option) error {
+				return client.Notify(context.Background(), server.URL, "notify", nil, hook)
+			},
+			method: "notify",
+		},
+		"CallStream": {
+			call: func(hook Option) error {
+				return client.CallStream(context.Background(), server.URL, "stream", nil, func(item json.RawMessage) error {
+					return nil
+				}, hook)
+			},
+			method: "stream",
+			idRaw:  true,
+		},
+		"CallBatch": {
+			call: func(hook Option) error {
+				_, err := client.CallBatch(context.Background(), server.URL, reqs, hook)
+				return err
+			},
+			batchSize: 2,
+		},
+		"CallBatchStream": {
+			call: func(hook Option) error {
+				return client.CallBatchStream(context.Background(), server.URL, reqs, func(req BatchRequest, resp BatchResponse) error {
+					return nil
+				}, hook)
+			},
+			batchSize: 2,
+		},
+		"CallBatchPartial": {
+			call: func(hook Option) error {
+				_, err := client.CallBatchPartial(context.Background(), server.URL, reqs, hook)
+				return err
+			},
+			batchSize: 2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var infos []CallInfo
+			hook := WithCallHook(func(ctx context.Context, info CallInfo) {
+				infos = append(infos, info)
+			})
+
+			if err := tt.call(hook); err != nil {
+				t.Fatalf("%s() failed: %v", name, err)
+			}
+
+			if len(infos) != 1 {
+				t.Fatalf("hook is called %d times, want 1", len(infos))
+			}
+			info := infos[0]
+			if info.Method != tt.method || info.BatchSize != tt.batchSize || info.Err != nil {
+				t.Errorf("CallInfo got %+v, want method %q and batch size %d without error", info, tt.method, tt.batchSize)
+			}
+			if got := len(info.IDRaw) > 0; got != tt.idRaw {
+				t.Errorf("CallInfo.IDRaw got %s, want set %v", info.IDRaw, tt.idRaw)
+			}
+		})
+	}
+}
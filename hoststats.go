@@ -0,0 +1,115 @@
+package jsonrpc
+
+import (
+	"net/url"
+	"sort"
+	"time"
+)
+
+// hostLatencySamples is the number of recent latencies kept per host to estimate the percentiles.
+const hostLatencySamples = 128
+
+// HostStat is statistics of the calls to a host.
+type HostStat struct {
+	// Success is the number of successful calls.
+	Success int64
+	// Failure is the number of failed calls.
+	Failure int64
+	// LastError is the error of the last failed call, or nil if no call failed.
+	LastError error
+	// P50 and P99 are the median and the 99th percentile of the latencies of recent calls.
+	P50 time.Duration
+	P99 time.Duration
+}
+
+// hostCounter counts the calls to a host.
+type hostCounter struct {
+	success   int64
+	failure   int64
+	lastError error
+
+	// latencies is a ring buffer of the latencies of recent calls.
+	latencies []time.Duration
+	next      int
+}
+
+func (c *hostCounter) record(d time.Duration, err error) {
+	if err != nil {
+		c.failure++
+		c.lastError = err
+	} else {
+		c.success++
+	}
+
+	if len(c.latencies) < hostLatencySamples {
+		c.latencies = append(c.latencies, d)
+		return
+	}
+	c.latencies[c.next] = d
+	c.next = (c.next + 1) % hostLatencySamples
+}
+
+func (c *hostCounter) stat() HostStat {
+	stat := HostStat{
+		Success:   c.success,
+		Failure:   c.failure,
+		LastError: c.lastError,
+	}
+
+	if len(c.latencies) > 0 {
+		sorted := append([]time.Duration(nil), c.latencies...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i] < sorted[j]
+		})
+		stat.P50 = percentile(sorted, 50)
+		stat.P99 = percentile(sorted, 99)
+	}
+
+	return stat
+}
+
+// percentile returns the p-th percentile of sorted by the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// recordHost records the result of a call to the host of rawURL.
+func (client *Client) recordHost(rawURL string, d time.Duration, err error) {
+	if rawURL == "" {
+		rawURL = client.Endpoint
+	}
+	u, perr := url.Parse(rawURL)
+	if perr != nil || u.Host == "" {
+		return
+	}
+
+	client.hostStatsMu.Lock()
+	defer client.hostStatsMu.Unlock()
+
+	if client.hostStats == nil {
+		client.hostStats = make(map[string]*hostCounter)
+	}
+	c, ok := client.hostStats[u.Host]
+	if !ok {
+		c = &hostCounter{}
+		client.hostStats[u.Host] = c
+	}
+	c.record(d, err)
+}
+
+// HostStats returns the statistics of the calls per host, keyed by the host of the url, e.g. "example.com:8080".
+// The latencies are estimated from the last 128 calls to each host.
+func (client *Client) HostStats() map[string]HostStat {
+	client.hostStatsMu.Lock()
+	defer client.hostStatsMu.Unlock()
+
+	stats := make(map[string]HostStat, len(client.hostStats))
+	for host, c := range client.hostStats {
+		stats[host] = c.stat()
+	}
+	return stats
+}
@@ -0,0 +1,73 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClientHostStats(t *testing.T) {
+	working := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer working.Close()
+
+	failing := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+	}))
+	defer failing.Close()
+
+	client := &Client{}
+
+	var result string
+	for i := 0; i < 3; i++ {
+		if err := client.Call(context.Background(), working.URL, "test", nil, &result); err != nil {
+			t.Fatalf("Client.Call() failed: %v", err)
+		}
+	}
+	if err := client.Call(context.Background(), failing.URL, "test", nil, &result); err == nil {
+		t.Fatal("Client.Call() must fail")
+	}
+
+	host := func(rawURL string) string {
+		u, _ := url.Parse(rawURL)
+		return u.Host
+	}
+
+	stats := client.HostStats()
+	if len(stats) != 2 {
+		t.Fatalf("Client.HostStats() got %d hosts, want 2", len(stats))
+	}
+
+	ws := stats[host(working.URL)]
+	if ws.Success != 3 || ws.Failure != 0 || ws.LastError != nil {
+		t.Errorf("stat of working host got %+v, want 3 successes", ws)
+	}
+	if ws.P50 <= 0 || ws.P99 < ws.P50 {
+		t.Errorf("latencies of working host got P50 %v, P99 %v", ws.P50, ws.P99)
+	}
+
+	fs := stats[host(failing.URL)]
+	if fs.Success != 0 || fs.Failure != 1 || fs.LastError == nil {
+		t.Errorf("stat of failing host got %+v, want 1 failure with the error", fs)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	if got := percentile(sorted, 50); got != 50*time.Millisecond {
+		t.Errorf("percentile(50) got %v, want %v", got, 50*time.Millisecond)
+	}
+	if got := percentile(sorted, 99); got != 99*time.Millisecond {
+		t.Errorf("percentile(99) got %v, want %v", got, 99*time.Millisecond)
+	}
+	if got := percentile(sorted[:1], 99); got != time.Millisecond {
+		t.Errorf("percentile(99) of one sample got %v, want %v", got, time.Millisecond)
+	}
+}
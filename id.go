@@ -0,0 +1,64 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// requestID is the id of a request sent by the client.
+// It is a UUID generated by the client, or an id given by WithIDSequence kept as its compacted JSON.
+// It is comparable, so that the responses can be matched to the requests by a map.
+// The zero value is the id of a notification.
+type requestID struct {
+	uuid uuid.UUID
+	// raw is the compacted JSON of the id which is not a string of a UUID, e.g. 1.
+	raw string
+}
+
+// newRequestID returns a new random id.
+func newRequestID() requestID {
+	return requestID{uuid: uuid.New()}
+}
+
+// parseRequestID parses the JSON of a non-null id.
+// A string of a UUID is parsed as the UUID, so that it matches the id whatever its case is.
+func parseRequestID(raw json.RawMessage) (requestID, error) {
+	if isNullJSON(raw) {
+		return requestID{}, errors.New("id must not be null")
+	}
+
+	var id uuid.UUID
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return requestID{uuid: id}, nil
+	}
+
+	key, err := rawIDKey(raw)
+	if err != nil {
+		return requestID{}, err
+	}
+	switch key[0] {
+	case '"', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+	default:
+		return requestID{}, errors.New("id must be a string or a number")
+	}
+
+	return requestID{raw: key}, nil
+}
+
+func (id requestID) MarshalJSON() ([]byte, error) {
+	if id.raw != "" {
+		return []byte(id.raw), nil
+	}
+
+	return json.Marshal(id.uuid)
+}
+
+func (id requestID) String() string {
+	if id.raw != "" {
+		return id.raw
+	}
+
+	return id.uuid.String()
+}
@@ -0,0 +1,60 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrIDSequenceExhausted is returned when a call needs a request id after all the ids of WithIDSequence are used.
+var ErrIDSequenceExhausted = errors.New("id sequence is exhausted")
+
+// WithIDSequence returns an Option that uses ids as the request ids in order instead of generating them,
+// e.g. to replay a captured session in tests.
+// The sequence is shared by all the calls given the returned Option, and each id is consumed by a request,
+// including the requests of a batch and the retries with WithFreshIDOnRetry.
+// The ids are JSON strings or numbers, e.g. json.RawMessage(`1`), and sent as they are given.
+// The responses are matched to the ids by their compacted JSON, except that strings of UUIDs are matched as UUIDs.
+//
+// The call fails with ErrIDSequenceExhausted if the sequence is exhausted.
+func WithIDSequence(ids ...json.RawMessage) Option {
+	seq := &idSequence{ids: append([]json.RawMessage(nil), ids...)}
+	return optionFunc(func(opts *callOptions) {
+		opts.idSequence = seq
+	})
+}
+
+// idSequence is a sequence of request ids given by WithIDSequence.
+type idSequence struct {
+	mu  sync.Mutex
+	ids []json.RawMessage
+}
+
+// next consumes the next id of the sequence.
+func (seq *idSequence) next() (requestID, error) {
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	if len(seq.ids) == 0 {
+		return requestID{}, ErrIDSequenceExhausted
+	}
+	raw := seq.ids[0]
+	seq.ids = seq.ids[1:]
+
+	id, err := parseRequestID(raw)
+	if err != nil {
+		return requestID{}, fmt.Errorf("invalid id %s in sequence: %w", raw, err)
+	}
+
+	return id, nil
+}
+
+// newID returns the next id of the sequence of WithIDSequence if it is set, otherwise a new UUID.
+func (opts *callOptions) newID() (requestID, error) {
+	if opts.idSequence == nil {
+		return newRequestID(), nil
+	}
+
+	return opts.idSequence.next()
+}
@@ -0,0 +1,122 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientCallWithIDSequence(t *testing.T) {
+	var recorded []string
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		recorded = append(recorded, string(req.ID))
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	// the ids captured in the session to replay.
+	captured := []string{
+		`"6f1a3c52-0b7e-4b8e-9a57-0d1c2e3f4a5b"`,
+		`"7a2b4d63-1c8f-4c9f-8b68-1e2d3f4a5b6c"`,
+		`"8b3c5e74-2d90-4da0-9c79-2f3e4a5b6c7d"`,
+	}
+	ids := make([]json.RawMessage, len(captured))
+	for i, id := range captured {
+		ids[i] = json.RawMessage(id)
+	}
+	seq := WithIDSequence(ids...)
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "echo", nil, &result, seq); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if _, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Result: new(string)},
+		{Method: "echo", Result: new(string)},
+	}, seq); err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(recorded, captured) {
+		t.Errorf("server got ids %v, want %v", recorded, captured)
+	}
+
+	err := client.Call(context.Background(), server.URL, "echo", nil, &result, seq)
+	if !errors.Is(err, ErrIDSequenceExhausted) {
+		t.Errorf("Client.Call() error got %v, want %v", err, ErrIDSequenceExhausted)
+	}
+}
+
+func TestClientCallWithIDSequenceIntegers(t *testing.T) {
+	var recorded []string
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		recorded = append(recorded, string(req.ID))
+		if req.Method == "items" {
+			return []string{"ok"}, nil
+		}
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	captured := []string{`1`, `2`, `3`, `"four"`}
+	ids := make([]json.RawMessage, len(captured))
+	for i, id := range captured {
+		ids[i] = json.RawMessage(id)
+	}
+	seq := WithIDSequence(ids...)
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "echo", nil, &result, seq); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if _, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Result: new(string)},
+		{Method: "echo", Result: new(string)},
+	}, seq); err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	if err := client.CallStream(context.Background(), server.URL, "items", nil, func(item json.RawMessage) error {
+		return nil
+	}, seq); err != nil {
+		t.Fatalf("Client.CallStream() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(recorded, captured) {
+		t.Errorf("server got ids %v, want %v", recorded, captured)
+	}
+}
+
+func TestClientCallWithIDSequenceInvalid(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]string{
+		"null":   `null`,
+		"object": `{"id":1}`,
+		"array":  `[1]`,
+		"bool":   `true`,
+		"broken": `"1`,
+	}
+
+	for name, id := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			err := client.Call(context.Background(), server.URL, "echo", nil, &result, WithIDSequence(json.RawMessage(id)))
+			if err == nil || !strings.Contains(err.Error(), "invalid id") {
+				t.Errorf("Client.Call() error got %v, want invalid id", err)
+			}
+		})
+	}
+}
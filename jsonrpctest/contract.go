@@ -0,0 +1,180 @@
+// Package jsonrpctest provides utilities for testing the clients of JSON-RPC.
+package jsonrpctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/kechako/go-jsonrpc"
+)
+
+// ContractTransport is an http.RoundTripper which serves JSON-RPC calls without a server,
+// for the contract tests of the clients.
+// It validates the params of each call against the JSON Schema registered for the method,
+// and responds the canned result of the method.
+//
+// A call with the params not conforming to the schema fails the test,
+// and is responded with an InvalidParams error.
+// A call of a method not registered fails the test, and is responded with a MethodNotFound error.
+//
+// It is safe for concurrent use.
+type ContractTransport struct {
+	t testing.TB
+
+	mu        sync.Mutex
+	contracts map[string]contract
+}
+
+type contract struct {
+	schema interface{}
+	result json.RawMessage
+}
+
+// NewContractTransport returns a new ContractTransport reporting the violations of the contracts to t.
+func NewContractTransport(t testing.TB) *ContractTransport {
+	return &ContractTransport{
+		t:         t,
+		contracts: make(map[string]contract),
+	}
+}
+
+// Register registers the contract of the method, which validates the params by schema,
+// and responds result marshaled to JSON.
+// See Validate for the keywords of JSON Schema supported.
+func (ct *ContractTransport) Register(method string, schema string, result interface{}) {
+	ct.t.Helper()
+
+	var s interface{}
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		ct.t.Fatalf("failed to decode the schema of %s: %v", method, err)
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		ct.t.Fatalf("failed to marshal the result of %s: %v", method, err)
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	ct.contracts[method] = contract{
+		schema: s,
+		result: b,
+	}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      json.RawMessage        `json:"id"`
+	Result  json.RawMessage        `json:"result,omitempty"`
+	Error   *jsonrpc.ResponseError `json:"error,omitempty"`
+}
+
+// RoundTrip implements http.RoundTripper.
+func (ct *ContractTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	var out interface{}
+	if b := bytes.TrimSpace(body); len(b) > 0 && b[0] == '[' {
+		var reqs []request
+		if err := json.Unmarshal(b, &reqs); err != nil {
+			return nil, fmt.Errorf("failed to decode batch request: %w", err)
+		}
+		var resps []response
+		for _, r := range reqs {
+			if res, ok := ct.serve(r); ok {
+				resps = append(resps, res)
+			}
+		}
+		if len(resps) > 0 {
+			out = resps
+		}
+	} else {
+		var r request
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		if res, ok := ct.serve(r); ok {
+			out = res
+		}
+	}
+
+	var resBody []byte
+	if out != nil {
+		b, err := json.Marshal(out)
+		if err != nil {
+			return nil, err
+		}
+		resBody = b
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
+
+// serve serves the request r, and reports whether it is responded.
+// A notification, which has no id, is not responded.
+func (ct *ContractTransport) serve(r request) (response, bool) {
+	ct.mu.Lock()
+	c, ok := ct.contracts[r.Method]
+	ct.mu.Unlock()
+
+	res := response{
+		JSONRPC: jsonrpc.Version,
+		ID:      r.ID,
+	}
+	if !ok {
+		ct.t.Errorf("method %s is called without the contract", r.Method)
+		res.Error = &jsonrpc.ResponseError{Code: jsonrpc.MethodNotFound, Message: "method not found"}
+	} else if err := validateParams(c.schema, r.Params); err != nil {
+		ct.t.Errorf("params of %s violate the contract: %v", r.Method, err)
+		res.Error = &jsonrpc.ResponseError{Code: jsonrpc.InvalidParams, Message: err.Error()}
+	} else {
+		res.Result = c.result
+	}
+
+	if len(r.ID) == 0 || string(r.ID) == "null" {
+		return response{}, false
+	}
+	return res, true
+}
+
+// validateParams validates the raw params against schema. Missing params are validated as null.
+func validateParams(schema interface{}, params json.RawMessage) error {
+	var v interface{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &v); err != nil {
+			return fmt.Errorf("failed to decode params: %w", err)
+		}
+	}
+
+	return Validate(schema, v)
+}
@@ -0,0 +1,131 @@
+package jsonrpctest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/kechako/go-jsonrpc"
+)
+
+// recordingTB records the errors reported by ContractTransport instead of failing the test.
+type recordingTB struct {
+	testing.TB
+
+	mu     sync.Mutex
+	errors []string
+}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+const createUserSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"required": ["name"],
+	"additionalProperties": false
+}`
+
+func newContractClient(t *testing.T) (*jsonrpc.Client, *recordingTB) {
+	tb := &recordingTB{TB: t}
+	ct := NewContractTransport(tb)
+	ct.Register("createUser", createUserSchema, map[string]int{"id": 1})
+
+	return &jsonrpc.Client{HTTPClient: &http.Client{Transport: ct}}, tb
+}
+
+func TestContractTransport(t *testing.T) {
+	tests := map[string]struct {
+		method  string
+		params  interface{}
+		wantErr jsonrpc.ErrorCode
+	}{
+		"conforming": {
+			method: "createUser",
+			params: map[string]interface{}{"name": "alice", "age": 20},
+		},
+		"missing required": {
+			method:  "createUser",
+			params:  map[string]interface{}{"age": 20},
+			wantErr: jsonrpc.InvalidParams,
+		},
+		"wrong type": {
+			method:  "createUser",
+			params:  map[string]interface{}{"name": "alice", "age": 20.5},
+			wantErr: jsonrpc.InvalidParams,
+		},
+		"additional property": {
+			method:  "createUser",
+			params:  map[string]interface{}{"name": "alice", "admin": true},
+			wantErr: jsonrpc.InvalidParams,
+		},
+		"unknown method": {
+			method:  "deleteUser",
+			params:  map[string]interface{}{"id": 1},
+			wantErr: jsonrpc.MethodNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, tb := newContractClient(t)
+
+			var result map[string]int
+			err := client.Call(context.Background(), "http://contract.test/rpc", tt.method, tt.params, &result)
+
+			if tt.wantErr == 0 {
+				if err != nil {
+					t.Fatalf("Client.Call() failed: %v", err)
+				}
+				if result["id"] != 1 {
+					t.Errorf("Client.Call() got %v, want the canned result", result)
+				}
+				if len(tb.errors) != 0 {
+					t.Errorf("ContractTransport reported %v, want no violation", tb.errors)
+				}
+				return
+			}
+
+			var rpcErr *jsonrpc.ResponseError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != tt.wantErr {
+				t.Errorf("Client.Call() error got %v, want %d", err, tt.wantErr)
+			}
+			if len(tb.errors) != 1 {
+				t.Errorf("ContractTransport reported %v, want a violation", tb.errors)
+			}
+		})
+	}
+}
+
+func TestContractTransportBatch(t *testing.T) {
+	client, tb := newContractClient(t)
+
+	var first, second map[string]int
+	res, err := client.CallBatch(context.Background(), "http://contract.test/rpc", []jsonrpc.BatchRequest{
+		{Method: "createUser", Params: map[string]string{"name": "alice"}, Result: &first},
+		{Method: "createUser", Params: map[string]string{"name": ""}, Result: &second},
+		{Method: "createUser", Params: map[string]string{"name": "bob"}, Notification: true},
+	})
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+	if first["id"] != 1 {
+		t.Errorf("result of request 0 got %v, want the canned result", first)
+	}
+	if e := res.Responses[1].Error; e == nil || e.Code != jsonrpc.InvalidParams {
+		t.Errorf("error of request 1 got %v, want InvalidParams", e)
+	}
+	if len(tb.errors) != 1 {
+		t.Errorf("ContractTransport reported %v, want a violation", tb.errors)
+	}
+}
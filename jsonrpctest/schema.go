@@ -0,0 +1,188 @@
+package jsonrpctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+)
+
+// Validate validates v, a value decoded from JSON by encoding/json, against schema,
+// a JSON Schema decoded in the same way.
+//
+// Only the following keywords are supported, and the others are ignored:
+//
+//   - type, enum, const
+//   - properties, required, additionalProperties
+//   - items, minItems, maxItems
+//   - minLength, maxLength, minimum, maximum
+func Validate(schema interface{}, v interface{}) error {
+	return validate(schema, v, "$")
+}
+
+func validate(schema interface{}, v interface{}, path string) error {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			return fmt.Errorf("%s: no value is allowed", path)
+		}
+		return nil
+	case map[string]interface{}:
+		return validateObject(s, v, path)
+	}
+
+	return fmt.Errorf("%s: invalid schema %v", path, schema)
+}
+
+func validateObject(s map[string]interface{}, v interface{}, path string) error {
+	if t, ok := s["type"]; ok && !matchType(t, v) {
+		return fmt.Errorf("%s: %s is not of type %v", path, typeOf(v), t)
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %s is not one of %s", path, marshal(v), marshal(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, v) {
+		return fmt.Errorf("%s: %s is not %s", path, marshal(v), marshal(c))
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return validateProperties(s, v, path)
+	case []interface{}:
+		if n, ok := number(s["minItems"]); ok && float64(len(v)) < n {
+			return fmt.Errorf("%s: %d items are fewer than %v", path, len(v), n)
+		}
+		if n, ok := number(s["maxItems"]); ok && float64(len(v)) > n {
+			return fmt.Errorf("%s: %d items are more than %v", path, len(v), n)
+		}
+		if items, ok := s["items"]; ok {
+			for i, item := range v {
+				if err := validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		l := float64(utf8.RuneCountInString(v))
+		if n, ok := number(s["minLength"]); ok && l < n {
+			return fmt.Errorf("%s: %q is shorter than %v", path, v, n)
+		}
+		if n, ok := number(s["maxLength"]); ok && l > n {
+			return fmt.Errorf("%s: %q is longer than %v", path, v, n)
+		}
+	case float64:
+		if n, ok := number(s["minimum"]); ok && v < n {
+			return fmt.Errorf("%s: %v is less than %v", path, v, n)
+		}
+		if n, ok := number(s["maximum"]); ok && v > n {
+			return fmt.Errorf("%s: %v is greater than %v", path, v, n)
+		}
+	}
+
+	return nil
+}
+
+func validateProperties(s map[string]interface{}, v map[string]interface{}, path string) error {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			name, _ := name.(string)
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: required property %q is missing", path, name)
+			}
+		}
+	}
+
+	props, _ := s["properties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := path + "." + name
+		if prop, ok := props[name]; ok {
+			if err := validate(prop, v[name], p); err != nil {
+				return err
+			}
+			continue
+		}
+		if hasAdditional {
+			if b, ok := additional.(bool); ok && !b {
+				return fmt.Errorf("%s: additional property %q is not allowed", path, name)
+			}
+			if err := validate(additional, v[name], p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchType reports whether v matches the type keyword t, either a type name or an array of them.
+func matchType(t interface{}, v interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		if t == "integer" {
+			f, ok := v.(float64)
+			return ok && f == math.Trunc(f)
+		}
+		if t == "number" {
+			_, ok := v.(float64)
+			return ok
+		}
+		return typeOf(v) == t
+	case []interface{}:
+		for _, t := range t {
+			if matchType(t, v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func number(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func marshal(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
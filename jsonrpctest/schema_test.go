@@ -0,0 +1,45 @@
+package jsonrpctest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		schema  string
+		value   string
+		wantErr bool
+	}{
+		"type":              {schema: `{"type":"string"}`, value: `"a"`},
+		"type mismatch":     {schema: `{"type":"string"}`, value: `1`, wantErr: true},
+		"type array":        {schema: `{"type":["string","null"]}`, value: `null`},
+		"integer":           {schema: `{"type":"integer"}`, value: `1.5`, wantErr: true},
+		"enum":              {schema: `{"enum":["a","b"]}`, value: `"c"`, wantErr: true},
+		"const":             {schema: `{"const":1}`, value: `1`},
+		"items":             {schema: `{"items":{"type":"number"}}`, value: `[1,"2"]`, wantErr: true},
+		"minItems":          {schema: `{"minItems":2}`, value: `[1]`, wantErr: true},
+		"maxLength":         {schema: `{"maxLength":2}`, value: `"abc"`, wantErr: true},
+		"maximum":           {schema: `{"maximum":10}`, value: `10`},
+		"nested":            {schema: `{"properties":{"a":{"properties":{"b":{"type":"string"}}}}}`, value: `{"a":{"b":1}}`, wantErr: true},
+		"additional schema": {schema: `{"additionalProperties":{"type":"number"}}`, value: `{"a":1,"b":2}`},
+		"false schema":      {schema: `false`, value: `1`, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var schema, value interface{}
+			if err := json.Unmarshal([]byte(tt.schema), &schema); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+
+			err := Validate(schema, value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
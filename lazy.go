@@ -0,0 +1,64 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Lazy is a result of a call fetched from the server, which is decoded on the first call of Get.
+// The zero value of Lazy is not usable.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	raw  json.RawMessage
+	opts callOptions
+
+	once   sync.Once
+	result T
+	err    error
+}
+
+// lazyRaw is a ResultScanner capturing the raw result,
+// so that the result is not passed to the unmarshaler given by WithResultUnmarshaler until Lazy.Get.
+type lazyRaw struct {
+	raw json.RawMessage
+}
+
+func (r *lazyRaw) ScanRPC(data json.RawMessage) error {
+	r.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// CallLazy calls the method on the url with the params like Client.Call,
+// but defers decoding the result until Lazy.Get is called.
+// The response is fetched before CallLazy returns, and the error of the call is returned by CallLazy.
+// The unmarshaler given by WithResultUnmarshaler is applied by Lazy.Get.
+func CallLazy[T any](ctx context.Context, client *Client, url string, method string, params interface{}, opts ...Option) (Lazy[T], error) {
+	var raw lazyRaw
+	if err := client.Call(ctx, url, method, params, &raw, opts...); err != nil {
+		return Lazy[T]{}, err
+	}
+
+	return Lazy[T]{
+		state: &lazyState[T]{
+			raw:  raw.raw,
+			opts: newCallOptions(opts),
+		},
+	}, nil
+}
+
+// Get decodes the result on the first call, and returns the decoded result.
+// The subsequent calls return the same result and error without decoding again.
+// It is safe to call Get from multiple goroutines.
+func (lazy Lazy[T]) Get() (T, error) {
+	s := lazy.state
+	s.once.Do(func() {
+		s.err = decodeResult(s.raw, &s.result, s.opts)
+		s.raw = nil
+	})
+
+	return s.result, s.err
+}
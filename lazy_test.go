@@ -0,0 +1,94 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// lazyDecodes counts the decodes of lazyResult, reset by each test using it.
+var lazyDecodes int32
+
+type lazyResult struct {
+	Value string
+}
+
+func (r *lazyResult) UnmarshalJSON(b []byte) error {
+	atomic.AddInt32(&lazyDecodes, 1)
+	return json.Unmarshal(b, &r.Value)
+}
+
+func TestCallLazy(t *testing.T) {
+	atomic.StoreInt32(&lazyDecodes, 0)
+
+	var calls int32
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		atomic.AddInt32(&calls, 1)
+		return "lazy", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	lazy, err := CallLazy[lazyResult](context.Background(), client, server.URL, "test", nil)
+	if err != nil {
+		t.Fatalf("CallLazy() failed: %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("server got %d calls, want 1", calls)
+	}
+	if decodes := atomic.LoadInt32(&lazyDecodes); decodes != 0 {
+		t.Fatalf("result decoded %d times before Lazy.Get(), want 0", decodes)
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := lazy.Get()
+		if err != nil {
+			t.Fatalf("Lazy.Get() failed: %v", err)
+		}
+		if got.Value != "lazy" {
+			t.Errorf("Lazy.Get() got %q, want %q", got.Value, "lazy")
+		}
+	}
+	if decodes := atomic.LoadInt32(&lazyDecodes); decodes != 1 {
+		t.Errorf("result decoded %d times, want 1", decodes)
+	}
+}
+
+func TestCallLazyWithResultUnmarshaler(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "lazy", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var targets []string
+	unmarshaler := WithResultUnmarshaler(func(raw json.RawMessage, result interface{}) error {
+		targets = append(targets, fmt.Sprintf("%T", result))
+		return json.Unmarshal(raw, result)
+	})
+
+	lazy, err := CallLazy[string](context.Background(), client, server.URL, "test", nil, unmarshaler)
+	if err != nil {
+		t.Fatalf("CallLazy() failed: %v", err)
+	}
+	if len(targets) != 0 {
+		t.Fatalf("ResultUnmarshaler called with %v before Lazy.Get(), want no calls", targets)
+	}
+
+	got, err := lazy.Get()
+	if err != nil {
+		t.Fatalf("Lazy.Get() failed: %v", err)
+	}
+	if got != "lazy" {
+		t.Errorf("Lazy.Get() got %q, want %q", got, "lazy")
+	}
+	if want := []string{"*string"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("ResultUnmarshaler called with %v, want %v", targets, want)
+	}
+}
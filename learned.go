@@ -0,0 +1,117 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WithLearnedTimeout returns a ClientOption that learns the timeout of each host from the header
+// of its responses, e.g. the maximum processing time advertised by the server,
+// and applies it to the later calls to the host, capped by maxTimeout.
+// The value of the header is either a number of seconds, e.g. "1.5", or a duration, e.g. "1500ms".
+//
+// The timeout is applied in addition to the deadline of the context, so the earlier one wins.
+// It is applied by Call and CallBatch.
+func WithLearnedTimeout(header string, maxTimeout time.Duration) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.LearnedTimeoutHeader = header
+		opts.LearnedTimeoutCap = maxTimeout
+	})
+}
+
+// learnedTimeouts keeps the timeouts learned for each host.
+type learnedTimeouts struct {
+	header     string
+	maxTimeout time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]time.Duration
+}
+
+// learn learns the timeout of host from the header of its response.
+func (lt *learnedTimeouts) learn(host string, header http.Header) {
+	if lt == nil {
+		return
+	}
+
+	d, ok := parseTimeout(header.Get(lt.header))
+	if !ok {
+		return
+	}
+	if lt.maxTimeout > 0 && d > lt.maxTimeout {
+		d = lt.maxTimeout
+	}
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	lt.hosts[host] = d
+}
+
+func (lt *learnedTimeouts) timeout(host string) (time.Duration, bool) {
+	if lt == nil {
+		return 0, false
+	}
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	d, ok := lt.hosts[host]
+	return d, ok
+}
+
+// parseTimeout parses the value of the header advertising the timeout.
+func parseTimeout(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs <= 0 {
+			return 0, false
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, true
+	}
+
+	return 0, false
+}
+
+// withTimeout returns the context of a call derived from ctx with the timeout given by WithTimeout
+// and the timeout learned for the host of rawURL, if any.
+func (client *Client) withTimeout(ctx context.Context, rawURL string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancelLearned := client.withLearnedTimeout(ctx, rawURL)
+	if timeout <= 0 {
+		return ctx, cancelLearned
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		cancelLearned()
+	}
+}
+
+// withLearnedTimeout returns ctx with the timeout learned for the host of rawURL, if any.
+func (client *Client) withLearnedTimeout(ctx context.Context, rawURL string) (context.Context, context.CancelFunc) {
+	if client.learnedTimeouts == nil {
+		return ctx, func() {}
+	}
+
+	u, err := url.Parse(client.endpoint(rawURL))
+	if err != nil {
+		return ctx, func() {}
+	}
+	d, ok := client.learnedTimeouts.timeout(u.Host)
+	if !ok {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, d)
+}
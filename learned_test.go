@@ -0,0 +1,74 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithLearnedTimeout(t *testing.T) {
+	const header = "X-Max-Processing-Time"
+
+	tests := map[string]struct {
+		advertised string
+		maxTimeout time.Duration
+		wantErr    bool
+	}{
+		"advertised": {
+			advertised: "0.05",
+			maxTimeout: time.Minute,
+			wantErr:    true,
+		},
+		"duration": {
+			advertised: "50ms",
+			maxTimeout: time.Minute,
+			wantErr:    true,
+		},
+		"capped": {
+			advertised: "60",
+			maxTimeout: 50 * time.Millisecond,
+			wantErr:    true,
+		},
+		"invalid": {
+			advertised: "soon",
+			maxTimeout: time.Minute,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+				if req.Method == "slow" {
+					time.Sleep(200 * time.Millisecond)
+				}
+				return "ok", nil
+			})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(header, tt.advertised)
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client := NewClient(WithLearnedTimeout(header, tt.maxTimeout))
+
+			var result string
+			if err := client.Call(context.Background(), server.URL, "fast", nil, &result); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+
+			err := client.Call(context.Background(), server.URL, "slow", nil, &result)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Client.Call() failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Client.Call() error got %v, want context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
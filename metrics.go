@@ -0,0 +1,101 @@
+package jsonrpc
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Metrics is a snapshot of the cumulative metrics of a Client, returned by MetricsSnapshot.
+type Metrics struct {
+	// Calls is the number of calls, including batch calls.
+	Calls int64
+	// Retries is the number of retried attempts.
+	Retries int64
+
+	// ResponseErrors is the number of calls failed with a *ResponseError.
+	ResponseErrors int64
+	// StatusErrors is the number of calls failed with a *StatusError.
+	StatusErrors int64
+	// ProtocolErrors is the number of calls failed with a *ProtocolError.
+	ProtocolErrors int64
+	// TransportErrors is the number of calls failed because the request could not be sent or the response could not be received.
+	TransportErrors int64
+	// ContextErrors is the number of calls failed with a *ContextError.
+	ContextErrors int64
+	// OtherErrors is the number of calls failed with other errors.
+	OtherErrors int64
+
+	// BytesSent and BytesReceived are the bytes transferred, same as Client.Transferred.
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// Errors returns the total number of failed calls.
+func (m Metrics) Errors() int64 {
+	return m.ResponseErrors + m.StatusErrors + m.ProtocolErrors + m.TransportErrors + m.ContextErrors + m.OtherErrors
+}
+
+// clientMetrics is the cumulative metrics of a Client, updated atomically.
+type clientMetrics struct {
+	calls   int64
+	retries int64
+
+	responseErrors  int64
+	statusErrors    int64
+	protocolErrors  int64
+	transportErrors int64
+	contextErrors   int64
+	otherErrors     int64
+}
+
+func (m *clientMetrics) recordCall(err error) {
+	atomic.AddInt64(&m.calls, 1)
+	if err == nil {
+		return
+	}
+
+	var (
+		rpcErr    *ResponseError
+		statusErr *StatusError
+		protoErr  *ProtocolError
+		ctxErr    *ContextError
+	)
+	switch {
+	case errors.As(err, &ctxErr):
+		atomic.AddInt64(&m.contextErrors, 1)
+	case errors.As(err, &rpcErr):
+		atomic.AddInt64(&m.responseErrors, 1)
+	case errors.As(err, &statusErr):
+		atomic.AddInt64(&m.statusErrors, 1)
+	case errors.As(err, &protoErr):
+		atomic.AddInt64(&m.protocolErrors, 1)
+	case isTransportError(err):
+		atomic.AddInt64(&m.transportErrors, 1)
+	default:
+		atomic.AddInt64(&m.otherErrors, 1)
+	}
+}
+
+func (m *clientMetrics) recordRetry() {
+	atomic.AddInt64(&m.retries, 1)
+}
+
+// MetricsSnapshot returns a snapshot of the cumulative metrics of the client.
+// It is a lightweight way to assert the calls of the client, e.g. in tests.
+func (client *Client) MetricsSnapshot() Metrics {
+	m := &client.metrics
+	sent, recv := client.Transferred()
+
+	return Metrics{
+		Calls:           atomic.LoadInt64(&m.calls),
+		Retries:         atomic.LoadInt64(&m.retries),
+		ResponseErrors:  atomic.LoadInt64(&m.responseErrors),
+		StatusErrors:    atomic.LoadInt64(&m.statusErrors),
+		ProtocolErrors:  atomic.LoadInt64(&m.protocolErrors),
+		TransportErrors: atomic.LoadInt64(&m.transportErrors),
+		ContextErrors:   atomic.LoadInt64(&m.contextErrors),
+		OtherErrors:     atomic.LoadInt64(&m.otherErrors),
+		BytesSent:       sent,
+		BytesReceived:   recv,
+	}
+}
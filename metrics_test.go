@@ -0,0 +1,62 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestClientMetricsSnapshot(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "fail" {
+			return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+		}
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer flaky.Close()
+
+	client := &Client{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var result string
+			if err := client.Call(context.Background(), server.URL, "test", nil, &result); err != nil {
+				t.Errorf("Client.Call() failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var result string
+	client.Call(context.Background(), server.URL, "fail", nil, &result)
+	client.Call(context.Background(), flaky.URL, "test", nil, &result, WithRetry(2, nil))
+	client.Call(context.Background(), "http://"+refusedAddr(t), "test", nil, &result)
+
+	m := client.MetricsSnapshot()
+	if m.Calls != 7 {
+		t.Errorf("Metrics.Calls got %d, want 7", m.Calls)
+	}
+	if m.Errors() != 3 {
+		t.Errorf("Metrics.Errors() got %d, want 3", m.Errors())
+	}
+	if m.ResponseErrors != 1 || m.StatusErrors != 1 || m.TransportErrors != 1 {
+		t.Errorf("Metrics got %+v, want one response, status and transport error each", m)
+	}
+	if m.Retries != 2 {
+		t.Errorf("Metrics.Retries got %d, want 2", m.Retries)
+	}
+	if m.BytesSent <= 0 || m.BytesReceived <= 0 {
+		t.Errorf("Metrics got %d bytes sent and %d bytes received, want positive", m.BytesSent, m.BytesReceived)
+	}
+}
@@ -0,0 +1,225 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Notify sends a notification of the method with the params to the url.
+// Notify is strictly fire-and-forget: the server does not respond to a notification,
+// and even if it does, the response body is discarded without being decoded.
+// 200 OK and 204 No Content are accepted as the confirmed delivery,
+// as well as the statuses accepted by AcceptStatus of the Client or WithAcceptStatus.
+// Other statuses fail with *StatusError.
+func (client *Client) Notify(ctx context.Context, url string, method string, params interface{}, opts ...Option) error {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	if method == "" {
+		return errors.New("method is empty")
+	}
+
+	callOpts := newCallOptions(opts)
+	callOpts.method = method
+
+	params, err := callOpts.marshalParams(method, params)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(callOpts.EnvelopeKeyCase.notificationEnvelope(&notification{
+		JSONRPC: Version,
+		Method:  method,
+		Params:  params,
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	if err := callOpts.checkRequestSize(body); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = client.sendNotification(ctx, url, body, callOpts)
+	err = contextError(ctx, client.queueOffline(url, method, params, true, err, callOpts))
+	callOpts.runHooks(ctx, CallInfo{
+		Method:   method,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+
+	return err
+}
+
+// sendNotification sends the body of a notification, or a batch containing only notifications, to the url.
+// The server may respond with no content, so 204 No Content is accepted in addition to the statuses accepted for calls,
+// and the body is discarded.
+// The returned response is only for its metadata, its body is already closed when it returns.
+func (client *Client) sendNotification(ctx context.Context, url string, body []byte, callOpts callOptions) (*http.Response, error) {
+	res, err := client.do(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(res.Body)
+
+	if res.StatusCode != http.StatusNoContent && !client.acceptStatus(res.StatusCode, callOpts) {
+		return nil, &StatusError{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+		}
+	}
+
+	if callOpts.StrictNotifications {
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			return nil, &ProtocolError{
+				Kind:    NotificationResponded,
+				Message: fmt.Sprintf("server responds to a notification with a body of %d bytes", len(b)),
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// WithStrictNotifications returns an Option that makes Notify fail with a ProtocolError of NotificationResponded
+// if the server responds to the notification with a non-empty body.
+// It is also applied to a batch containing only notifications.
+// By default, the body is drained and ignored.
+func WithStrictNotifications() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.StrictNotifications = true
+	})
+}
+
+// NotificationBuffer accumulates notifications, and sends them as a single batch
+// when the number of them reaches the maximum size or the interval elapses after the first one is added.
+// It is safe for concurrent use.
+type NotificationBuffer struct {
+	client   *Client
+	url      string
+	maxSize  int
+	interval time.Duration
+	opts     []Option
+
+	mu    sync.Mutex
+	reqs  []BatchRequest
+	timer Timer
+	err   error
+}
+
+// NewNotificationBuffer returns a new NotificationBuffer which sends notifications to the url.
+// The notifications are flushed when maxSize notifications are added, or interval elapses after the first one is added.
+// The size trigger is disabled if maxSize is 0, and the time trigger is disabled if interval is 0.
+// opts are used for the batch calls.
+//
+// The timer uses the clock of the Client, see WithClock.
+func (client *Client) NewNotificationBuffer(url string, maxSize int, interval time.Duration, opts ...Option) *NotificationBuffer {
+	return &NotificationBuffer{
+		client:   client,
+		url:      url,
+		maxSize:  maxSize,
+		interval: interval,
+		opts:     opts,
+	}
+}
+
+// Add adds a notification of the method with the params to the buffer.
+// If the buffer reaches the maximum size, the notifications are flushed before Add returns,
+// and the error of the flush is returned.
+// An error of the previous flush triggered by the interval is also returned by Add.
+func (buf *NotificationBuffer) Add(method string, params interface{}) error {
+	buf.mu.Lock()
+	buf.reqs = append(buf.reqs, BatchRequest{
+		Method:       method,
+		Params:       params,
+		Notification: true,
+	})
+
+	if buf.maxSize > 0 && len(buf.reqs) >= buf.maxSize {
+		reqs, err := buf.take()
+		buf.mu.Unlock()
+
+		if ferr := buf.send(context.Background(), reqs); ferr != nil {
+			return ferr
+		}
+		return err
+	}
+
+	if len(buf.reqs) == 1 && buf.interval > 0 {
+		buf.timer = buf.client.clockOrDefault().AfterFunc(buf.interval, buf.flushByTimer)
+	}
+
+	err := buf.err
+	buf.err = nil
+	buf.mu.Unlock()
+
+	return err
+}
+
+// Flush sends the buffered notifications as a single batch.
+// An error of the previous flush triggered by the interval is returned if no error occurs on this flush.
+func (buf *NotificationBuffer) Flush(ctx context.Context) error {
+	buf.mu.Lock()
+	reqs, err := buf.take()
+	buf.mu.Unlock()
+
+	if ferr := buf.send(ctx, reqs); ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+// take takes the buffered notifications and the error of the previous flush, and stops the timer.
+// buf.mu must be held.
+func (buf *NotificationBuffer) take() ([]BatchRequest, error) {
+	if buf.timer != nil {
+		buf.timer.Stop()
+		buf.timer = nil
+	}
+
+	reqs, err := buf.reqs, buf.err
+	buf.reqs, buf.err = nil, nil
+
+	return reqs, err
+}
+
+func (buf *NotificationBuffer) send(ctx context.Context, reqs []BatchRequest) error {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	_, err := buf.client.CallBatch(ctx, buf.url, reqs, buf.opts...)
+	return err
+}
+
+func (buf *NotificationBuffer) flushByTimer() {
+	buf.mu.Lock()
+	reqs, prevErr := buf.take()
+	buf.mu.Unlock()
+
+	err := buf.send(context.Background(), reqs)
+	if err == nil {
+		err = prevErr
+	}
+	if err == nil {
+		return
+	}
+
+	buf.mu.Lock()
+	if buf.err == nil {
+		buf.err = err
+	}
+	buf.mu.Unlock()
+}
@@ -0,0 +1,258 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// notificationServer returns a server which records the methods of each batch.
+func notificationServer(t *testing.T) (*httptest.Server, func() [][]string) {
+	var mu sync.Mutex
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*testRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		var methods []string
+		for _, req := range reqs {
+			if req.ID != nil {
+				t.Errorf("request %s has id %s, want notification", req.Method, req.ID)
+			}
+			methods = append(methods, req.Method)
+		}
+
+		mu.Lock()
+		batches = append(batches, methods)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	return server, func() [][]string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([][]string(nil), batches...)
+	}
+}
+
+func TestNotificationBufferFlushBySize(t *testing.T) {
+	server, batches := notificationServer(t)
+	defer server.Close()
+
+	client := NewClient(WithClock(newFakeClock()))
+	buf := client.NewNotificationBuffer(server.URL, 2, time.Minute)
+
+	for _, method := range []string{"first", "second", "third"} {
+		if err := buf.Add(method, nil); err != nil {
+			t.Fatalf("NotificationBuffer.Add() failed: %v", err)
+		}
+	}
+
+	got := batches()
+	if len(got) != 1 || len(got[0]) != 2 {
+		t.Fatalf("server got batches %v, want a batch of 2 notifications", got)
+	}
+
+	if err := buf.Flush(context.Background()); err != nil {
+		t.Fatalf("NotificationBuffer.Flush() failed: %v", err)
+	}
+
+	got = batches()
+	if len(got) != 2 || len(got[1]) != 1 || got[1][0] != "third" {
+		t.Errorf("server got batches %v, want the second batch of third", got)
+	}
+}
+
+func TestNotificationBufferFlushByTime(t *testing.T) {
+	server, batches := notificationServer(t)
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(WithClock(clock))
+	buf := client.NewNotificationBuffer(server.URL, 10, time.Second)
+
+	if err := buf.Add("first", nil); err != nil {
+		t.Fatalf("NotificationBuffer.Add() failed: %v", err)
+	}
+	if err := buf.Add("second", nil); err != nil {
+		t.Fatalf("NotificationBuffer.Add() failed: %v", err)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if got := batches(); len(got) != 0 {
+		t.Fatalf("server got batches %v before the interval, want none", got)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	got := batches()
+	if len(got) != 1 || len(got[0]) != 2 {
+		t.Errorf("server got batches %v, want a batch of 2 notifications", got)
+	}
+}
+
+func TestClientNotify(t *testing.T) {
+	tests := map[string]struct {
+		status int
+		body   string
+	}{
+		"no content": {
+			status: http.StatusNoContent,
+		},
+		"empty": {
+			status: http.StatusOK,
+		},
+		"result": {
+			status: http.StatusOK,
+			body:   `{"jsonrpc":"2.0","result":{"unexpected":true},"id":null}`,
+		},
+		"invalid JSON": {
+			status: http.StatusOK,
+			body:   `not a JSON`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var raw map[string]json.RawMessage
+				if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				if _, ok := raw["id"]; ok {
+					t.Errorf("notification has id %s", raw["id"])
+				}
+				if got := string(raw["method"]); got != `"refresh"` {
+					t.Errorf("method got %s, want %s", got, `"refresh"`)
+				}
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			if err := client.Notify(context.Background(), server.URL, "refresh", []string{"key"}); err != nil {
+				t.Errorf("Client.Notify() failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientNotifyAcceptStatus(t *testing.T) {
+	tests := map[string]struct {
+		status  int
+		opts    []Option
+		wantErr bool
+	}{
+		"internal server error": {
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+		"accepted": {
+			status:  http.StatusAccepted,
+			wantErr: true,
+		},
+		"accepted by option": {
+			status: http.StatusAccepted,
+			opts:   []Option{WithAcceptStatus(http.StatusAccepted)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			err := client.Notify(context.Background(), server.URL, "refresh", nil, tt.opts...)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Client.Notify() failed: %v", err)
+				}
+				return
+			}
+			var serr *StatusError
+			if !errors.As(err, &serr) || serr.StatusCode != tt.status {
+				t.Errorf("Client.Notify() error got %v, want StatusError of %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestClientNotifyStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	err := client.Notify(context.Background(), server.URL, "refresh", nil)
+
+	var serr *StatusError
+	if !errors.As(err, &serr) || serr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Client.Notify() error got %v, want *StatusError of 503", err)
+	}
+}
+
+func TestClientNotifyWithStrictNotifications(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		wantErr bool
+	}{
+		"no body": {
+			body:    "",
+			wantErr: false,
+		},
+		"whitespace": {
+			body:    "\n",
+			wantErr: false,
+		},
+		"responded": {
+			body:    `{"jsonrpc":"2.0","result":"ok","id":null}`,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			if err := client.Notify(context.Background(), server.URL, "refresh", nil); err != nil {
+				t.Fatalf("Client.Notify() failed without WithStrictNotifications: %v", err)
+			}
+
+			err := client.Notify(context.Background(), server.URL, "refresh", nil, WithStrictNotifications())
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Client.Notify() failed: %v", err)
+				}
+				return
+			}
+
+			var perr *ProtocolError
+			if !errors.As(err, &perr) || perr.Kind != NotificationResponded {
+				t.Errorf("Client.Notify() error got %v, want ProtocolError of NotificationResponded", err)
+			}
+		})
+	}
+}
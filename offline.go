@@ -0,0 +1,236 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueuedRequest is a request queued by the offline queue of WithOfflineQueue.
+type QueuedRequest struct {
+	// ID identifies the request in the QueueStore.
+	ID string `json:"id"`
+	// URL is the url the request is sent to.
+	URL string `json:"url"`
+	// Method is the method name of the request.
+	Method string `json:"method"`
+	// Params is the marshaled params of the request, empty if it has no params.
+	Params json.RawMessage `json:"params,omitempty"`
+	// Notification reports whether the request is a notification.
+	Notification bool `json:"notification,omitempty"`
+	// QueuedAt is the time the request is queued.
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// QueueStore persists the requests queued by the offline queue of WithOfflineQueue,
+// e.g. into a file or a database, so that they survive the restart of the process.
+// It must be safe for concurrent use.
+type QueueStore interface {
+	// Enqueue persists req at the end of the queue.
+	Enqueue(req QueuedRequest) error
+	// Pending returns the requests in the queue in the queued order.
+	Pending() ([]QueuedRequest, error)
+	// Remove removes the request of the id from the queue.
+	Remove(id string) error
+}
+
+// QueuedError is returned when a request fails because the server cannot be reached,
+// and is queued by the offline queue of WithOfflineQueue to be sent later.
+type QueuedError struct {
+	// Request is the queued request.
+	Request QueuedRequest `json:"request"`
+	// Err is the error sending the request.
+	Err error `json:"-"`
+}
+
+func (err *QueuedError) Error() string {
+	return fmt.Sprintf("request is queued for offline delivery: %v", err.Err)
+}
+
+func (err *QueuedError) Unwrap() error {
+	return err.Err
+}
+
+// WithOfflineQueue returns a ClientOption that queues the requests failing because the server cannot be reached,
+// i.e. failing to resolve the host name or to establish a connection, into store to send them later.
+// Notifications are always queued, and calls are queued only with WithQueueIfOffline.
+// The queued requests fail with a *QueuedError.
+//
+// The queue is drained in the background when a call or notification of the Client succeeds again
+// while requests may be queued, i.e. after a request is queued or the Client is created with a store
+// which may persist requests, or by FlushOfflineQueue. The drain in the background is canceled by Shutdown.
+// The queued requests are sent without the options of the original calls,
+// and the results of the calls are discarded.
+func WithOfflineQueue(store QueueStore) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.OfflineQueue = store
+	})
+}
+
+// WithQueueIfOffline returns an Option that queues the call into the offline queue of WithOfflineQueue
+// if the server cannot be reached. The call is sent again later with its result discarded,
+// so it is only for methods which are idempotent and whose results are not needed.
+func WithQueueIfOffline() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.QueueIfOffline = true
+	})
+}
+
+// withOfflineFlush returns an Option that marks the call as sent by the flush of the offline queue,
+// so that it is neither queued again nor triggers another flush.
+func withOfflineFlush() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.offlineFlush = true
+	})
+}
+
+// offlineQueue is the offline queue of a Client.
+type offlineQueue struct {
+	store QueueStore
+
+	// mu serializes the flushes.
+	mu sync.Mutex
+	// flushing is 1 while a flush is running in the background.
+	flushing int32
+	// pending is 1 if the store may have queued requests.
+	pending int32
+}
+
+// newOfflineQueue returns an offlineQueue of store.
+// The store may have the requests persisted before, so the first success flushes it.
+func newOfflineQueue(store QueueStore) *offlineQueue {
+	return &offlineQueue{
+		store:   store,
+		pending: 1,
+	}
+}
+
+// queueOffline queues the request of the method failed with err into the offline queue,
+// and returns a *QueuedError. It returns err as is if the request is not queued.
+// If the request succeeds, it starts draining the queue in the background if requests may be queued.
+func (client *Client) queueOffline(url string, method string, params interface{}, notification bool, err error, callOpts callOptions) error {
+	q := client.offline
+	if q == nil || callOpts.offlineFlush {
+		return err
+	}
+
+	if err == nil {
+		client.flushOfflineBackground()
+		return nil
+	}
+
+	if !notProcessed(err) || (!notification && !callOpts.QueueIfOffline) {
+		return err
+	}
+
+	req := QueuedRequest{
+		ID:           uuid.New().String(),
+		URL:          url,
+		Method:       method,
+		Notification: notification,
+		QueuedAt:     client.clockOrDefault().Now(),
+	}
+	if params != nil {
+		params, merr := callOpts.marshalParams(method, params)
+		if merr != nil {
+			return err
+		}
+		b, merr := json.Marshal(params)
+		if merr != nil {
+			return err
+		}
+		req.Params = b
+	}
+
+	if qerr := q.store.Enqueue(req); qerr != nil {
+		return fmt.Errorf("failed to queue request: %v: %w", qerr, err)
+	}
+	atomic.StoreInt32(&q.pending, 1)
+
+	return &QueuedError{
+		Request: req,
+		Err:     err,
+	}
+}
+
+// flushOfflineBackground starts draining the offline queue in the background,
+// unless the queue is known to be empty or a flush is already running.
+// The flush is tracked as an in-flight call, so that Shutdown cancels and waits for it.
+func (client *Client) flushOfflineBackground() {
+	q := client.offline
+	if atomic.LoadInt32(&q.pending) == 0 || !atomic.CompareAndSwapInt32(&q.flushing, 0, 1) {
+		return
+	}
+
+	ctx, done := client.begin(context.Background())
+	if ctx.Err() != nil {
+		done()
+		atomic.StoreInt32(&q.flushing, 0)
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&q.flushing, 0)
+		defer done()
+		client.FlushOfflineQueue(ctx)
+	}()
+}
+
+// FlushOfflineQueue sends the requests in the offline queue of WithOfflineQueue in the queued order,
+// and removes them from the queue.
+// It stops and returns the error if a request fails by a transport error or a 5xx response,
+// leaving it and the following ones in the queue. A request failing otherwise, e.g. by an error response, is removed.
+func (client *Client) FlushOfflineQueue(ctx context.Context) error {
+	q := client.offline
+	if q == nil {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// pending is cleared before loading the requests, so that the ones queued during the flush are flushed later.
+	atomic.StoreInt32(&q.pending, 0)
+	if err := client.flushOffline(ctx, q); err != nil {
+		atomic.StoreInt32(&q.pending, 1)
+		return err
+	}
+
+	return nil
+}
+
+// flushOffline sends the requests in q in the queued order, and removes them from q.
+func (client *Client) flushOffline(ctx context.Context, q *offlineQueue) error {
+	reqs, err := q.store.Pending()
+	if err != nil {
+		return fmt.Errorf("failed to load queued requests: %w", err)
+	}
+
+	for _, req := range reqs {
+		var params interface{}
+		if len(req.Params) > 0 {
+			params = req.Params
+		}
+
+		var err error
+		if req.Notification {
+			err = client.Notify(ctx, req.URL, req.Method, params, withOfflineFlush())
+		} else {
+			err = client.Call(ctx, req.URL, req.Method, params, discardResult{}, withOfflineFlush())
+		}
+		if err != nil && (degraded(err) || ctx.Err() != nil) {
+			return err
+		}
+
+		if err := q.store.Remove(req.ID); err != nil {
+			return fmt.Errorf("failed to remove queued request: %w", err)
+		}
+	}
+
+	return nil
+}
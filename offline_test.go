@@ -0,0 +1,205 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryQueueStore is a QueueStore in memory.
+type memoryQueueStore struct {
+	mu   sync.Mutex
+	reqs []QueuedRequest
+}
+
+func (store *memoryQueueStore) Enqueue(req QueuedRequest) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.reqs = append(store.reqs, req)
+	return nil
+}
+
+func (store *memoryQueueStore) Pending() ([]QueuedRequest, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return append([]QueuedRequest(nil), store.reqs...), nil
+}
+
+func (store *memoryQueueStore) Remove(id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for i, req := range store.reqs {
+		if req.ID == id {
+			store.reqs = append(store.reqs[:i], store.reqs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestClientOfflineQueue(t *testing.T) {
+	received := make(chan string, 10)
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		received <- req.Method + ":" + string(req.Params)
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	var offline int32 = 1
+	dialer := &net.Dialer{}
+	store := &memoryQueueStore{}
+	client := NewClient(WithOfflineQueue(store))
+	client.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if atomic.LoadInt32(&offline) == 1 {
+					return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("network is unreachable")}
+				}
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	// offline: the notifications and the idempotent call are queued, the other call is not.
+	for _, method := range []string{"first", "second"} {
+		err := client.Notify(context.Background(), server.URL, method, []string{method})
+		var queued *QueuedError
+		if !errors.As(err, &queued) || queued.Request.Method != method {
+			t.Fatalf("Client.Notify() error got %v, want *QueuedError", err)
+		}
+	}
+	if err := client.Call(context.Background(), server.URL, "put", []int{1}, nil, WithQueueIfOffline()); !errors.As(err, new(*QueuedError)) {
+		t.Fatalf("Client.Call() error got %v, want *QueuedError", err)
+	}
+	if err := client.Call(context.Background(), server.URL, "post", nil, nil); err == nil || errors.As(err, new(*QueuedError)) {
+		t.Fatalf("Client.Call() error got %v, want not queued", err)
+	}
+	if reqs, _ := store.Pending(); len(reqs) != 3 {
+		t.Fatalf("QueueStore got %d requests, want 3", len(reqs))
+	}
+
+	// online: the success of a notification drains the queue in the background.
+	atomic.StoreInt32(&offline, 0)
+	if err := client.Notify(context.Background(), server.URL, "online", nil); err != nil {
+		t.Fatalf("Client.Notify() failed: %v", err)
+	}
+
+	want := []string{"online:", `first:["first"]`, `second:["second"]`, "put:[1]"}
+	for _, w := range want {
+		select {
+		case got := <-received:
+			if got != w {
+				t.Errorf("server got %s, want %s", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("server did not receive %s", w)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		reqs, _ := store.Pending()
+		if len(reqs) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("QueueStore got %d requests after the flush, want 0", len(reqs))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// countingQueueStore is a memoryQueueStore counting the calls of Pending.
+type countingQueueStore struct {
+	memoryQueueStore
+	pending int32
+}
+
+func (store *countingQueueStore) Pending() ([]QueuedRequest, error) {
+	atomic.AddInt32(&store.pending, 1)
+	return store.memoryQueueStore.Pending()
+}
+
+func TestClientOfflineQueueFlushOnlyPending(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	store := &countingQueueStore{}
+	client := NewClient(WithOfflineQueue(store))
+
+	var result string
+	for i := 0; i < 5; i++ {
+		if err := client.Call(context.Background(), server.URL, "test", nil, &result); err != nil {
+			t.Fatalf("Client.Call() failed: %v", err)
+		}
+		// Shutdown is not called here; wait for the background flush of the first call.
+		deadline := time.Now().Add(5 * time.Second)
+		for atomic.LoadInt32(&client.offline.flushing) == 1 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	if got := atomic.LoadInt32(&store.pending); got != 1 {
+		t.Errorf("QueueStore.Pending() got %d calls, want 1", got)
+	}
+}
+
+func TestClientOfflineQueueShutdown(t *testing.T) {
+	blocked := make(chan struct{})
+	canceled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/queued" {
+			ioutil.ReadAll(r.Body)
+			close(blocked)
+			<-r.Context().Done()
+			close(canceled)
+			return
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	store := &memoryQueueStore{}
+	store.Enqueue(QueuedRequest{ID: "queued", URL: server.URL + "/queued", Method: "queued", Notification: true})
+	client := NewClient(WithOfflineQueue(store))
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "test", nil, &result); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	select {
+	case <-blocked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not receive the queued request")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatalf("Client.Shutdown() failed: %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the flush in the background is not canceled by Shutdown")
+	}
+
+	if reqs, _ := store.Pending(); len(reqs) != 1 {
+		t.Errorf("QueueStore got %d requests after Shutdown, want 1", len(reqs))
+	}
+}
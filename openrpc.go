@@ -0,0 +1,138 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// DiscoverMethod is the method name of the OpenRPC service discovery.
+const DiscoverMethod = "rpc.discover"
+
+// OpenRPCDocument is an OpenRPC document describing the methods of a server.
+// Only the members used by the client are decoded.
+type OpenRPCDocument struct {
+	// OpenRPC is the version of the OpenRPC specification.
+	OpenRPC string `json:"openrpc"`
+	// Info is the metadata of the API.
+	Info OpenRPCInfo `json:"info"`
+	// Methods is the methods of the API.
+	Methods []OpenRPCMethod `json:"methods"`
+}
+
+// OpenRPCInfo is the metadata of the API described by an OpenRPCDocument.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// The values of OpenRPCMethod.ParamStructure.
+const (
+	ParamsByName     = "by-name"
+	ParamsByPosition = "by-position"
+	ParamsEither     = "either"
+)
+
+// OpenRPCMethod is a method described by an OpenRPCDocument.
+type OpenRPCMethod struct {
+	// Name is the method name.
+	Name string `json:"name"`
+	// Params is the params of the method in the declared order.
+	Params []OpenRPCContentDescriptor `json:"params"`
+	// Result is the result of the method, nil for a notification.
+	Result *OpenRPCContentDescriptor `json:"result,omitempty"`
+	// ParamStructure is how the params are sent, ParamsByName, ParamsByPosition or ParamsEither.
+	// It is ParamsEither if empty.
+	ParamStructure string `json:"paramStructure,omitempty"`
+}
+
+// OpenRPCContentDescriptor describes a param or a result of an OpenRPCMethod.
+type OpenRPCContentDescriptor struct {
+	Name     string          `json:"name"`
+	Required bool            `json:"required,omitempty"`
+	Schema   json.RawMessage `json:"schema,omitempty"`
+}
+
+// Discover calls DiscoverMethod, and returns the OpenRPC document responded by the server.
+func (client *Client) Discover(ctx context.Context, url string, opts ...Option) (*OpenRPCDocument, error) {
+	var doc OpenRPCDocument
+	if err := client.Call(ctx, url, DiscoverMethod, nil, &doc, opts...); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// method returns the method of the name, or nil if it is not described.
+func (doc *OpenRPCDocument) method(name string) *OpenRPCMethod {
+	if doc == nil {
+		return nil
+	}
+
+	for i := range doc.Methods {
+		if doc.Methods[i].Name == name {
+			return &doc.Methods[i]
+		}
+	}
+
+	return nil
+}
+
+// WithParamConversionFromSchema returns an Option that converts the named params given as an object
+// to the positional params in the order declared by doc, for the methods declared as ParamsByPosition.
+// e.g. {"b":2,"a":1} is sent as [1,2] for the method declaring the params a and b.
+// The missing params are sent as null, except the trailing ones which are omitted.
+//
+// The params of the other methods, and the ones not marshaled to an object, are sent as is.
+// The call fails if the params have a name not declared by the method.
+func WithParamConversionFromSchema(doc *OpenRPCDocument) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ParamConversion = doc
+	})
+}
+
+// positional returns the method of the name if its params are converted to positional, otherwise nil.
+func (doc *OpenRPCDocument) positional(name string) *OpenRPCMethod {
+	m := doc.method(name)
+	if m == nil || m.ParamStructure != ParamsByPosition {
+		return nil
+	}
+
+	return m
+}
+
+// toPositional converts the named params raw to the positional params in the declared order.
+func (m *OpenRPCMethod) toPositional(raw json.RawMessage) (json.RawMessage, error) {
+	if b := bytes.TrimSpace(raw); len(b) == 0 || b[0] != '{' {
+		return raw, nil
+	}
+
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &named); err != nil {
+		return nil, fmt.Errorf("failed to decode params: %w", err)
+	}
+
+	positional := make([]json.RawMessage, len(m.Params))
+	last := 0
+	for i, param := range m.Params {
+		v, ok := named[param.Name]
+		if !ok {
+			positional[i] = json.RawMessage("null")
+			continue
+		}
+		positional[i] = v
+		last = i + 1
+		delete(named, param.Name)
+	}
+	for name := range named {
+		return nil, fmt.Errorf("failed to convert params of %s: unknown param %q", m.Name, name)
+	}
+
+	b, err := json.Marshal(positional[:last])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,106 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+const testOpenRPCDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "test", "version": "1.0.0"},
+	"methods": [
+		{
+			"name": "subtract",
+			"params": [
+				{"name": "minuend", "required": true, "schema": {"type": "integer"}},
+				{"name": "subtrahend", "required": true, "schema": {"type": "integer"}},
+				{"name": "scale", "schema": {"type": "integer"}}
+			],
+			"result": {"name": "difference", "schema": {"type": "integer"}},
+			"paramStructure": "by-position"
+		},
+		{
+			"name": "greet",
+			"params": [{"name": "name", "schema": {"type": "string"}}],
+			"result": {"name": "greeting", "schema": {"type": "string"}},
+			"paramStructure": "by-name"
+		}
+	]
+}`
+
+func TestClientCallWithParamConversionFromSchema(t *testing.T) {
+	var gotParams string
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == DiscoverMethod {
+			return json.RawMessage(testOpenRPCDocument), nil
+		}
+		gotParams = string(req.Params)
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	doc, err := client.Discover(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Client.Discover() failed: %v", err)
+	}
+	if len(doc.Methods) != 2 {
+		t.Fatalf("Client.Discover() got %d methods, want 2", len(doc.Methods))
+	}
+
+	tests := map[string]struct {
+		method  string
+		params  interface{}
+		want    string
+		wantErr bool
+	}{
+		"by-position": {
+			method: "subtract",
+			params: map[string]int{"subtrahend": 23, "minuend": 42},
+			want:   `[42,23]`,
+		},
+		"missing param": {
+			method: "subtract",
+			params: map[string]int{"scale": 2, "minuend": 42},
+			want:   `[42,null,2]`,
+		},
+		"already positional": {
+			method: "subtract",
+			params: []int{42, 23},
+			want:   `[42,23]`,
+		},
+		"by-name": {
+			method: "greet",
+			params: map[string]string{"name": "gopher"},
+			want:   `{"name":"gopher"}`,
+		},
+		"unknown param": {
+			method:  "subtract",
+			params:  map[string]int{"minuend": 42, "divisor": 2},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotParams = ""
+			var result string
+			err := client.Call(context.Background(), server.URL, tt.method, tt.params, &result, WithParamConversionFromSchema(doc))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Client.Call() got params %s, want error", gotParams)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if gotParams != tt.want {
+				t.Errorf("Client.Call() sent params %s, want %s", gotParams, tt.want)
+			}
+		})
+	}
+}
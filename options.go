@@ -0,0 +1,103 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResolvedOptions is a read-only view of Options applied by ApplyOptions.
+// It is intended to assert the options composed for calls in tests, without sending a request.
+type ResolvedOptions struct {
+	opts callOptions
+}
+
+// ApplyOptions applies opts in the same way as a call, and returns the resolved options.
+func ApplyOptions(opts ...Option) ResolvedOptions {
+	return ResolvedOptions{
+		opts: newCallOptions(opts),
+	}
+}
+
+// Options returns an Option that applies opts in order,
+// e.g. to bundle the options commonly used by the calls into a single value.
+func Options(opts ...Option) Option {
+	opts = append([]Option(nil), opts...)
+	return optionFunc(func(callOpts *callOptions) {
+		for _, opt := range opts {
+			opt.apply(callOpts)
+		}
+	})
+}
+
+// Header returns a copy of the header set by WithHeader.
+func (r ResolvedOptions) Header() http.Header {
+	return r.opts.Header.Clone()
+}
+
+// HTTPVersion returns the major HTTP version forced by WithForceHTTP1 or WithForceHTTP2,
+// or 0 if the version is not forced.
+func (r ResolvedOptions) HTTPVersion() int {
+	switch r.opts.HTTPVersion {
+	case http1:
+		return 1
+	case http2:
+		return 2
+	}
+	return 0
+}
+
+// TransferBudget returns the budget set by WithTransferBudget.
+func (r ResolvedOptions) TransferBudget() int64 {
+	return r.opts.TransferBudget
+}
+
+// ExpectNoResult reports whether WithExpectNoResult is applied.
+func (r ResolvedOptions) ExpectNoResult() bool {
+	return r.opts.ExpectNoResult
+}
+
+// MaxRetries returns the maximum number of retries set by WithRetry.
+func (r ResolvedOptions) MaxRetries() int {
+	return r.opts.MaxRetries
+}
+
+// RetrySafeOnly reports whether WithRetrySafeOnly is applied.
+func (r ResolvedOptions) RetrySafeOnly() bool {
+	return r.opts.RetrySafeOnly
+}
+
+// Timeout returns the timeout of the call set by WithTimeout.
+func (r ResolvedOptions) Timeout() time.Duration {
+	return r.opts.Timeout
+}
+
+// BodyReadTimeout returns the timeout set by WithBodyReadTimeout.
+func (r ResolvedOptions) BodyReadTimeout() time.Duration {
+	return r.opts.BodyReadTimeout
+}
+
+// ContentTypeCharset returns the charset set by WithContentTypeCharset,
+// or the default charset if it is not set.
+func (r ResolvedOptions) ContentTypeCharset() string {
+	if r.opts.ContentTypeCharset == "" {
+		return defaultCharset
+	}
+	return r.opts.ContentTypeCharset
+}
+
+// ArtificialLatency returns the latency set by WithArtificialLatency.
+func (r ResolvedOptions) ArtificialLatency() time.Duration {
+	return r.opts.ArtificialLatency
+}
+
+// CacheControl returns the max-age set for the method by WithCacheControl,
+// and reports whether it is set.
+func (r ResolvedOptions) CacheControl(method string) (time.Duration, bool) {
+	maxAge, ok := r.opts.CacheControl[method]
+	return maxAge, ok
+}
+
+// Hooks returns the number of hooks added by WithCallHook.
+func (r ResolvedOptions) Hooks() int {
+	return len(r.opts.Hooks)
+}
@@ -0,0 +1,94 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApplyOptions(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Test", "test")
+
+	resolved := ApplyOptions(
+		WithHeader(header),
+		WithForceHTTP2(),
+		WithRetry(3, nil),
+		WithRetrySafeOnly(),
+		WithTimeout(5*time.Second),
+		WithBodyReadTimeout(time.Second),
+		WithCacheControl("user.get", time.Minute),
+		WithCallHook(func(ctx context.Context, info CallInfo) {}),
+	)
+
+	if got := resolved.Header().Get("X-Test"); got != "test" {
+		t.Errorf("ResolvedOptions.Header() got X-Test %q, want %q", got, "test")
+	}
+	if got := resolved.HTTPVersion(); got != 2 {
+		t.Errorf("ResolvedOptions.HTTPVersion() got %d, want 2", got)
+	}
+	if got := resolved.MaxRetries(); got != 3 {
+		t.Errorf("ResolvedOptions.MaxRetries() got %d, want 3", got)
+	}
+	if !resolved.RetrySafeOnly() {
+		t.Error("ResolvedOptions.RetrySafeOnly() must be true")
+	}
+	if got := resolved.Timeout(); got != 5*time.Second {
+		t.Errorf("ResolvedOptions.Timeout() got %v, want %v", got, 5*time.Second)
+	}
+	if got := resolved.BodyReadTimeout(); got != time.Second {
+		t.Errorf("ResolvedOptions.BodyReadTimeout() got %v, want %v", got, time.Second)
+	}
+	if got, ok := resolved.CacheControl("user.get"); !ok || got != time.Minute {
+		t.Errorf("ResolvedOptions.CacheControl() got %v, %v, want %v, true", got, ok, time.Minute)
+	}
+	if _, ok := resolved.CacheControl("user.update"); ok {
+		t.Error("ResolvedOptions.CacheControl() must not be set for user.update")
+	}
+	if got := resolved.Hooks(); got != 1 {
+		t.Errorf("ResolvedOptions.Hooks() got %d, want 1", got)
+	}
+
+	resolved.Header().Set("X-Test", "modified")
+	if got := resolved.Header().Get("X-Test"); got != "test" {
+		t.Errorf("ResolvedOptions.Header() must return a copy, got X-Test %q", got)
+	}
+}
+
+func TestApplyOptionsDefault(t *testing.T) {
+	resolved := ApplyOptions()
+
+	if got := resolved.HTTPVersion(); got != 0 {
+		t.Errorf("ResolvedOptions.HTTPVersion() got %d, want 0", got)
+	}
+	if got := resolved.ContentTypeCharset(); got != defaultCharset {
+		t.Errorf("ResolvedOptions.ContentTypeCharset() got %q, want %q", got, defaultCharset)
+	}
+	if got := resolved.MaxRetries(); got != 0 {
+		t.Errorf("ResolvedOptions.MaxRetries() got %d, want 0", got)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	production := Options(
+		WithRetry(3, nil),
+		WithBodyReadTimeout(time.Second),
+		Options(WithForceHTTP2(), WithCallHook(func(ctx context.Context, info CallInfo) {})),
+	)
+
+	resolved := ApplyOptions(production, WithRetry(5, nil), WithCallHook(func(ctx context.Context, info CallInfo) {}))
+
+	if got := resolved.MaxRetries(); got != 5 {
+		t.Errorf("ResolvedOptions.MaxRetries() got %d, want 5 overridden after the bundle", got)
+	}
+	if got := resolved.BodyReadTimeout(); got != time.Second {
+		t.Errorf("ResolvedOptions.BodyReadTimeout() got %v, want %v", got, time.Second)
+	}
+	if got := resolved.HTTPVersion(); got != 2 {
+		t.Errorf("ResolvedOptions.HTTPVersion() got %d, want 2", got)
+	}
+	if got := resolved.Hooks(); got != 2 {
+		t.Errorf("ResolvedOptions.Hooks() got %d, want 2", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Package otelmetric records metrics of JSON-RPC calls with OpenTelemetry.
+//
+// It is provided as a separate module,
+// so that the users of jsonrpc who do not use OpenTelemetry do not depend on it.
+package otelmetric
+
+import (
+	"context"
+
+	"github.com/kechako/go-jsonrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const scopeName = "github.com/kechako/go-jsonrpc/otelmetric"
+
+// Names of the instruments recorded for each call.
+const (
+	CallsName    = "jsonrpc.client.calls"
+	ErrorsName   = "jsonrpc.client.errors"
+	DurationName = "jsonrpc.client.duration"
+)
+
+// Attribute keys recorded with the instruments.
+const (
+	MethodKey    = attribute.Key("rpc.method")
+	StatusKey    = attribute.Key("rpc.jsonrpc.status")
+	BatchSizeKey = attribute.Key("rpc.jsonrpc.batch_size")
+)
+
+// WithMeterProvider returns an Option that records the number of calls, the number of errors
+// and the latency of calls with the instruments created by mp.
+// The instruments are recorded with the method name and the status, either "ok" or "error".
+// A batch call is recorded as a single call with the number of its requests instead of the method name.
+//
+// The instruments are created when WithMeterProvider is called,
+// so the returned Option should be created once and reused for calls.
+// If an instrument cannot be created, the error is handled by otel.Handle,
+// and the instrument is replaced with a no-op one.
+func WithMeterProvider(mp metric.MeterProvider) jsonrpc.Option {
+	meter := mp.Meter(scopeName)
+	var fallback noop.Meter
+
+	calls, err := meter.Int64Counter(CallsName,
+		metric.WithDescription("Number of JSON-RPC calls."),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		otel.Handle(err)
+		calls, _ = fallback.Int64Counter(CallsName)
+	}
+	errs, err := meter.Int64Counter(ErrorsName,
+		metric.WithDescription("Number of failed JSON-RPC calls."),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		otel.Handle(err)
+		errs, _ = fallback.Int64Counter(ErrorsName)
+	}
+	duration, err := meter.Float64Histogram(DurationName,
+		metric.WithDescription("Duration of JSON-RPC calls."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+		duration, _ = fallback.Float64Histogram(DurationName)
+	}
+
+	return jsonrpc.WithCallHook(func(ctx context.Context, info jsonrpc.CallInfo) {
+		status := "ok"
+		if info.Err != nil {
+			status = "error"
+		}
+		target := MethodKey.String(info.Method)
+		if info.BatchSize > 0 {
+			target = BatchSizeKey.Int(info.BatchSize)
+		}
+		attrs := metric.WithAttributes(target, StatusKey.String(status))
+
+		calls.Add(ctx, 1, attrs)
+		if info.Err != nil {
+			errs.Add(ctx, 1, attrs)
+		}
+		duration.Record(ctx, info.Duration.Seconds(), attrs)
+	})
+}
@@ -0,0 +1,211 @@
+package otelmetric
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kechako/go-jsonrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func testServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		res := map[string]interface{}{
+			"jsonrpc": jsonrpc.Version,
+			"id":      req.ID,
+		}
+		if req.Method == "fail" {
+			res["error"] = map[string]interface{}{"code": jsonrpc.InternalError, "message": "internal error"}
+		} else {
+			res["result"] = "ok"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+}
+
+func TestWithMeterProvider(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	opt := WithMeterProvider(mp)
+
+	client := &jsonrpc.Client{}
+	ctx := context.Background()
+
+	var result string
+	for i := 0; i < 2; i++ {
+		if err := client.Call(ctx, server.URL, "test", nil, &result, opt); err != nil {
+			t.Fatalf("Client.Call() failed: %v", err)
+		}
+	}
+	if err := client.Call(ctx, server.URL, "fail", nil, &result, opt); err == nil {
+		t.Fatal("Client.Call() must fail")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	calls, ok := metrics[CallsName].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("%s got %T, want metricdata.Sum[int64]", CallsName, metrics[CallsName])
+	}
+	got := make(map[string]int64)
+	for _, dp := range calls.DataPoints {
+		method, _ := dp.Attributes.Value(MethodKey)
+		status, _ := dp.Attributes.Value(StatusKey)
+		got[method.AsString()+"/"+status.AsString()] = dp.Value
+	}
+	if got["test/ok"] != 2 || got["fail/error"] != 1 {
+		t.Errorf("%s got %v, want test/ok 2 and fail/error 1", CallsName, got)
+	}
+
+	errs, ok := metrics[ErrorsName].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("%s got %T, want metricdata.Sum[int64]", ErrorsName, metrics[ErrorsName])
+	}
+	if len(errs.DataPoints) != 1 || errs.DataPoints[0].Value != 1 {
+		t.Errorf("%s got %+v, want a single data point of 1", ErrorsName, errs.DataPoints)
+	}
+
+	duration, ok := metrics[DurationName].(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("%s got %T, want metricdata.Histogram[float64]", DurationName, metrics[DurationName])
+	}
+	var count uint64
+	for _, dp := range duration.DataPoints {
+		count += dp.Count
+	}
+	if count != 3 {
+		t.Errorf("%s got %d records, want 3", DurationName, count)
+	}
+}
+
+func TestWithMeterProviderBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		res := make([]map[string]interface{}, len(reqs))
+		for i, req := range reqs {
+			res[i] = map[string]interface{}{"jsonrpc": jsonrpc.Version, "id": req.ID, "result": "ok"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	client := &jsonrpc.Client{}
+	ctx := context.Background()
+
+	if _, err := client.CallBatch(ctx, server.URL, []jsonrpc.BatchRequest{{Method: "a"}, {Method: "b"}}, WithMeterProvider(mp)); err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	var found bool
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			calls, ok := m.Data.(metricdata.Sum[int64])
+			if m.Name != CallsName || !ok {
+				continue
+			}
+			for _, dp := range calls.DataPoints {
+				size, _ := dp.Attributes.Value(BatchSizeKey)
+				found = size.AsInt64() == 2 && dp.Value == 1
+			}
+		}
+	}
+	if !found {
+		t.Errorf("%s got %+v, want a call of batch size 2", CallsName, rm.ScopeMetrics)
+	}
+}
+
+// failingMeterProvider provides a meter which fails to create the instruments.
+type failingMeterProvider struct {
+	noop.MeterProvider
+}
+
+func (failingMeterProvider) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	return failingMeter{}
+}
+
+type failingMeter struct {
+	noop.Meter
+}
+
+var errInstrument = errors.New("failed to create instrument")
+
+func (failingMeter) Int64Counter(name string, opts ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return nil, errInstrument
+}
+
+func (failingMeter) Float64Histogram(name string, opts ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return nil, errInstrument
+}
+
+func TestWithMeterProviderInstrumentError(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	var handled int
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		if errors.Is(err, errInstrument) {
+			handled++
+		}
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {}))
+
+	opt := WithMeterProvider(failingMeterProvider{})
+	if handled != 3 {
+		t.Errorf("otel.Handle got %d errors, want 3", handled)
+	}
+
+	client := &jsonrpc.Client{}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "fail", nil, &result, opt); err == nil {
+		t.Fatal("Client.Call() must fail")
+	}
+}
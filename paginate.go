@@ -0,0 +1,74 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// WithBatchPagination returns an Option that makes CallBatch follow the cursors of the paginated results.
+// extract is called with the response of each page of a request,
+// and returns the params to request the next page and whether there are more pages.
+// The next pages of all the requests with more pages are requested by a follow-up batch of the same methods,
+// until every request is exhausted or fails.
+//
+// The raw results of all the pages of each request are aggregated into BatchResponse.Pages in order,
+// while the result of the first page is stored in the Result of the request as usual.
+// If a follow-up call fails, the error is set to BatchResponse.Error of the request.
+func WithBatchPagination(extract func(resp BatchResponse) (nextParams interface{}, more bool)) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.BatchPagination = extract
+	})
+}
+
+// paginateBatch requests the next pages of the requests of the batch by follow-up batches,
+// and aggregates the results into the responses of result.
+func (client *Client) paginateBatch(ctx context.Context, url string, reqs []BatchRequest, result *BatchResult, extract func(resp BatchResponse) (interface{}, bool), opts []Option) error {
+	var indexes []int
+	var next []BatchRequest
+	follow := func(i int, resp BatchResponse) {
+		if resp.Error != nil {
+			return
+		}
+		if params, more := extract(resp); more {
+			indexes = append(indexes, i)
+			next = append(next, BatchRequest{
+				Method:    reqs[i].Method,
+				Params:    params,
+				Validate:  reqs[i].Validate,
+				Unmarshal: reqs[i].Unmarshal,
+				Priority:  reqs[i].Priority,
+			})
+		}
+	}
+
+	resps := result.Responses
+	for i, req := range reqs {
+		if req.Notification || resps[i].Error != nil {
+			continue
+		}
+		resps[i].Pages = []json.RawMessage{resps[i].Result}
+		follow(i, resps[i])
+	}
+
+	for len(next) > 0 {
+		pending, pendingReqs := indexes, next
+		indexes, next = nil, nil
+
+		res, err := client.callBatch(ctx, url, pendingReqs, opts)
+		if err != nil {
+			return err
+		}
+
+		for j, resp := range res.Responses {
+			i := pending[j]
+			if resp.Error != nil {
+				resps[i].Error = resp.Error
+				continue
+			}
+			resps[i].Pages = append(resps[i].Pages, resp.Result)
+			follow(i, resp)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrStreamParams is returned when the params not known in advance are given by a ParamsEncoder
+// to a call other than Call, which cannot stream them into the request body.
+var ErrStreamParams = errors.New("params streamed by ParamsEncoder are supported only by Call")
+
+// ParamsEncoder encodes params by itself.
+// If the params passed to a call implement ParamsEncoder, it is used instead of json.Marshal.
+//
+// If the encoded params are known in advance, the request is sent with Content-Length,
+// otherwise the params are streamed into the request body with chunked transfer encoding.
+// Only Call streams the params, the other calls fail with ErrStreamParams for them.
+type ParamsEncoder interface {
+	// ParamsBytes returns the JSON encoded params.
+	// ok is false if the params are not known in advance and must be written by WriteParams.
+	ParamsBytes() (b []byte, ok bool, err error)
+	// WriteParams writes the JSON encoded params to w.
+	WriteParams(w io.Writer) error
+}
+
+type bytesParams []byte
+
+// BytesParams returns a ParamsEncoder of the JSON encoded params b.
+// The request is sent with Content-Length.
+func BytesParams(b []byte) ParamsEncoder {
+	return bytesParams(b)
+}
+
+func (p bytesParams) ParamsBytes() ([]byte, bool, error) {
+	return p, true, nil
+}
+
+func (p bytesParams) WriteParams(w io.Writer) error {
+	_, err := w.Write(p)
+	return err
+}
+
+type streamParams func(w io.Writer) error
+
+// StreamParams returns a ParamsEncoder which streams the params written by writeParams.
+// The request is sent with chunked transfer encoding.
+func StreamParams(writeParams func(w io.Writer) error) ParamsEncoder {
+	return streamParams(writeParams)
+}
+
+func (p streamParams) ParamsBytes() ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (p streamParams) WriteParams(w io.Writer) error {
+	return p(w)
+}
+
+// encodeParams returns the params encoded by the ParamsEncoder as json.RawMessage,
+// so that they are sent as is, or returns params as is if they are not a ParamsEncoder.
+func encodeParams(params interface{}) (interface{}, error) {
+	enc, ok := params.(ParamsEncoder)
+	if !ok {
+		return params, nil
+	}
+
+	b, known, err := enc.ParamsBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode params: %w", err)
+	}
+	if !known {
+		return nil, ErrStreamParams
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// CallFunc calls the method on the url with the params written by writeParams,
+// and stores result responded by the server in the result.
+// writeParams must write a JSON value of the params to w.
+// The params are streamed into the request body within the envelope,
+// so that large params are not materialized in memory.
+//
+// CallFunc is a shorthand for Call with StreamParams(writeParams).
+func (client *Client) CallFunc(ctx context.Context, url string, method string, writeParams func(w io.Writer) error, result interface{}, opts ...Option) error {
+	return client.Call(ctx, url, method, StreamParams(writeParams), result, opts...)
+}
+
+// callStream calls the method with the params streamed by writeParams.
+// The call is not retried, since the params cannot be written again.
+func (client *Client) callStream(ctx context.Context, url string, method string, writeParams func(w io.Writer) error, result interface{}, callOpts callOptions) error {
+	id, err := callOpts.newID()
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	writeErr := make(chan error, 1)
+	go func() {
+		err := writeEnvelope(pw, method, id, writeParams)
+		pw.CloseWithError(err)
+		writeErr <- err
+	}()
+
+	var scratch callScratch
+	err = scratch.detach(client.call(ctx, url, id, pr, result, &scratch, callOpts))
+
+	pr.Close()
+	if werr := <-writeErr; werr != nil && !errors.Is(werr, io.ErrClosedPipe) {
+		return fmt.Errorf("failed to write params: %w", werr)
+	}
+
+	return err
+}
+
+// writeEnvelope writes a request with the params written by writeParams to w.
+func writeEnvelope(w io.Writer, method string, id requestID, writeParams func(w io.Writer) error) error {
+	m, err := json.Marshal(method)
+	if err != nil {
+		return err
+	}
+	i, err := json.Marshal(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, `{"jsonrpc":"%s","method":%s,"id":%s,"params":`, Version, m, i); err != nil {
+		return err
+	}
+	if err := writeParams(w); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "}")
+
+	return err
+}
@@ -0,0 +1,414 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestClientCallFunc(t *testing.T) {
+	const n = 100000
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("request Content-Length got %d, want -1 (chunked)", r.ContentLength)
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			var params []int
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, &ResponseError{Code: InvalidParams, Message: err.Error()}
+			}
+			var sum int64
+			for _, p := range params {
+				sum += int64(p)
+			}
+			return map[string]int64{"count": int64(len(params)), "sum": sum}, nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	writeParams := func(w io.Writer) error {
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if _, err := io.WriteString(w, strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, "]")
+		return err
+	}
+
+	var result struct {
+		Count int   `json:"count"`
+		Sum   int64 `json:"sum"`
+	}
+	if err := client.CallFunc(context.Background(), server.URL, "sum", writeParams, &result); err != nil {
+		t.Fatalf("Client.CallFunc() failed: %v", err)
+	}
+
+	// the sum overflows int on 32-bit platforms.
+	if want := int64(n) * (n - 1) / 2; result.Count != n || result.Sum != want {
+		t.Errorf("Client.CallFunc() result got %+v, want count %d and sum %d", result, n, want)
+	}
+}
+
+func TestClientCallFuncWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	errWrite := errors.New("write error")
+	writeParams := func(w io.Writer) error {
+		return errWrite
+	}
+
+	var result string
+	err := client.CallFunc(context.Background(), server.URL, "fail", writeParams, &result)
+	if !errors.Is(err, errWrite) {
+		t.Errorf("Client.CallFunc() error got %v, want the write error", err)
+	}
+}
+
+func TestClientCallParamsEncoder(t *testing.T) {
+	var contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			var params []int
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, &ResponseError{Code: InvalidParams, Message: err.Error()}
+			}
+			return len(params), nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		params  ParamsEncoder
+		chunked bool
+	}{
+		"Bytes": {BytesParams([]byte("[1,2,3]")), false},
+		"Stream": {StreamParams(func(w io.Writer) error {
+			_, err := io.WriteString(w, "[1,2,3]")
+			return err
+		}), true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var count int
+			if err := client.Call(context.Background(), server.URL, "count", tt.params, &count); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if count != 3 {
+				t.Errorf("Client.Call() result got %d, want 3", count)
+			}
+
+			if tt.chunked && contentLength != -1 {
+				t.Errorf("request Content-Length got %d, want -1 (chunked)", contentLength)
+			}
+			if !tt.chunked && contentLength <= 0 {
+				t.Errorf("request Content-Length got %d, want the known length", contentLength)
+			}
+		})
+	}
+}
+
+func TestClientParamsEncoderCallPaths(t *testing.T) {
+	var mu sync.Mutex
+	var got []json.RawMessage
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		mu.Lock()
+		got = append(got, req.Params)
+		mu.Unlock()
+		return json.RawMessage(req.Params), nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	calls := map[string]func(params ParamsEncoder) error{
+		"Notify": func(params ParamsEncoder) error {
+			return client.Notify(context.Background(), server.URL, "echo", params)
+		},
+		"CallBatch": func(params ParamsEncoder) error {
+			_, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+				{Method: "echo", Params: params},
+			})
+			return err
+		},
+		"CallStream": func(params ParamsEncoder) error {
+			return client.CallStream(context.Background(), server.URL, "echo", params, func(item json.RawMessage) error {
+				return nil
+			})
+		},
+		"CallWithRawResponse": func(params ParamsEncoder) error {
+			_, cleanup, err := client.CallWithRawResponse(context.Background(), server.URL, "echo", params)
+			if err != nil {
+				return err
+			}
+			return cleanup()
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			got = nil
+			if err := call(BytesParams([]byte("[1,2]"))); err != nil {
+				t.Fatalf("%s() failed: %v", name, err)
+			}
+			if len(got) != 1 || string(got[0]) != "[1,2]" {
+				t.Errorf("%s() sent params %s, want [1,2]", name, got)
+			}
+
+			got = nil
+			err := call(StreamParams(func(w io.Writer) error {
+				_, err := io.WriteString(w, "[1,2]")
+				return err
+			}))
+			if !errors.Is(err, ErrStreamParams) {
+				t.Errorf("%s() with StreamParams error got %v, want %v", name, err, ErrStreamParams)
+			}
+			if len(got) != 0 {
+				t.Errorf("%s() with StreamParams sent %d requests, want 0", name, len(got))
+			}
+		})
+	}
+}
+
+// testPoint is a type which is not natively marshalable to the JSON the server expects.
+type testPoint struct {
+	x, y int
+}
+
+func TestClientCallWithParamsMarshaler(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return json.RawMessage(req.Params), nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	marshaler := WithParamsMarshaler(func(params interface{}) (json.RawMessage, error) {
+		if p, ok := params.(*testPoint); ok {
+			return json.RawMessage(fmt.Sprintf(`{"x":%d,"y":%d}`, p.x, p.y)), nil
+		}
+		return json.Marshal(params)
+	})
+
+	var result map[string]int
+	if err := client.Call(context.Background(), server.URL, "echo", &testPoint{x: 1, y: 2}, &result, marshaler); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if want := map[string]int{"x": 1, "y": 2}; !reflect.DeepEqual(result, want) {
+		t.Errorf("Client.Call() got %v, want %v", result, want)
+	}
+
+	var empty map[string]int
+	if err := client.Call(context.Background(), server.URL, "echo", &testPoint{x: 1, y: 2}, &empty); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("Client.Call() got %v without the marshaler, want empty", empty)
+	}
+}
+
+func TestClientCallWithParamsTemplate(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return json.RawMessage(req.Params), nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	template := WithParamsTemplate(map[string]interface{}{
+		"apiVersion": "v2",
+		"locale":     "en",
+	})
+
+	tests := map[string]struct {
+		params interface{}
+		want   interface{}
+	}{
+		"object": {
+			params: map[string]interface{}{"id": 1, "locale": "ja"},
+			want:   map[string]interface{}{"apiVersion": "v2", "locale": "ja", "id": float64(1)},
+		},
+		"struct": {
+			params: struct {
+				Name string `json:"name"`
+			}{Name: "gopher"},
+			want: map[string]interface{}{"apiVersion": "v2", "locale": "en", "name": "gopher"},
+		},
+		"array": {
+			params: []int{1, 2},
+			want:   []interface{}{float64(1), float64(2)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result interface{}
+			if err := client.Call(context.Background(), server.URL, "echo", tt.params, &result, template); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("Client.Call() got %v, want %v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCallWithEmptyParamsAs(t *testing.T) {
+	var members map[string]json.RawMessage
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		members = nil
+		if err := json.Unmarshal(body, &members); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		mode       EmptyParamsMode
+		params     interface{}
+		wantParams string // empty if omitted
+	}{
+		"omit": {
+			mode:   EmptyParamsOmit,
+			params: map[string]interface{}{},
+		},
+		"empty object": {
+			mode:       EmptyParamsObject,
+			params:     struct{}{},
+			wantParams: `{}`,
+		},
+		"null": {
+			mode:       EmptyParamsNull,
+			params:     map[string]interface{}{},
+			wantParams: `null`,
+		},
+		"not empty": {
+			mode:       EmptyParamsOmit,
+			params:     map[string]int{"id": 1},
+			wantParams: `{"id":1}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			if err := client.Call(context.Background(), server.URL, "echo", tt.params, &result, WithEmptyParamsAs(tt.mode)); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+
+			params, ok := members["params"]
+			if tt.wantParams == "" {
+				if ok {
+					t.Errorf("Client.Call() sent params %s, want omitted", params)
+				}
+				return
+			}
+			if string(params) != tt.wantParams {
+				t.Errorf("Client.Call() sent params %s, want %s", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestClientCallWithResultUnmarshaler(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return map[string]int{"x": 1, "y": 2}, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	unmarshaler := WithResultUnmarshaler(func(raw json.RawMessage, result interface{}) error {
+		if p, ok := result.(*testPoint); ok {
+			var v map[string]int
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			p.x, p.y = v["x"], v["y"]
+			return nil
+		}
+		return json.Unmarshal(raw, result)
+	})
+
+	var result testPoint
+	if err := client.Call(context.Background(), server.URL, "point", nil, &result, unmarshaler); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if want := (testPoint{x: 1, y: 2}); result != want {
+		t.Errorf("Client.Call() got %+v, want %+v", result, want)
+	}
+}
+
+// testScanned decodes a result of the "x,y" form.
+type testScanned struct {
+	point testPoint
+}
+
+func (s *testScanned) ScanRPC(data json.RawMessage) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	_, err := fmt.Sscanf(v, "%d,%d", &s.point.x, &s.point.y)
+	return err
+}
+
+func TestClientCallResultScanner(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "invalid" {
+			return "1;2", nil
+		}
+		return "1,2", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result testScanned
+	if err := client.Call(context.Background(), server.URL, "point", nil, &result); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if want := (testPoint{x: 1, y: 2}); result.point != want {
+		t.Errorf("Client.Call() got %+v, want %+v", result.point, want)
+	}
+
+	if err := client.Call(context.Background(), server.URL, "invalid", nil, &result); err == nil {
+		t.Error("Client.Call() with an invalid result must fail")
+	}
+}
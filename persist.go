@@ -0,0 +1,70 @@
+package jsonrpc
+
+import (
+	"github.com/google/uuid"
+)
+
+// BatchState is a state of a batch reported to the sink of WithBatchPersistence.
+type BatchState int
+
+const (
+	// BatchSent means that the batch is about to be sent, and is not confirmed yet.
+	BatchSent BatchState = iota + 1
+	// BatchCompleted means that the responses to the batch are received.
+	// Each request may still fail with the error in its response.
+	BatchCompleted
+	// BatchFailed means that the batch call fails without the responses,
+	// so whether the server processed the requests is unknown.
+	BatchFailed
+)
+
+func (state BatchState) String() string {
+	switch state {
+	case BatchSent:
+		return "Sent"
+	case BatchCompleted:
+		return "Completed"
+	case BatchFailed:
+		return "Failed"
+	}
+
+	return "Unknown"
+}
+
+// WithBatchPersistence returns an Option that calls sink when a batch is sent and when it completes or fails,
+// so that the batches in flight can be persisted and reconciled after a crash,
+// e.g. by retrying the unconfirmed requests against an idempotent server.
+// batchID identifies the batch, and ids are the request ids of the batch excluding the notifications.
+//
+// sink is called synchronously with BatchSent before the request is sent,
+// so the state can be persisted durably before the server processes the batch.
+func WithBatchPersistence(sink func(batchID string, ids []string, state BatchState)) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.BatchPersistence = sink
+	})
+}
+
+// persistBatch reports the batch of the request ids as sent to the BatchPersistence,
+// and returns a function to report the batch as completed or failed by the error of the call.
+func (opts *callOptions) persistBatch(ids []requestID) func(err error) {
+	if opts.BatchPersistence == nil {
+		return func(err error) {}
+	}
+
+	batchID := uuid.New().String()
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != (requestID{}) {
+			strs = append(strs, id.String())
+		}
+	}
+
+	opts.BatchPersistence(batchID, strs, BatchSent)
+	return func(err error) {
+		state := BatchCompleted
+		if err != nil {
+			state = BatchFailed
+		}
+		opts.BatchPersistence(batchID, strs, state)
+	}
+}
@@ -0,0 +1,59 @@
+package jsonrpc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// WithPinnedCertSHA256 returns a ClientOption that pins the leaf certificate of the servers
+// to the SHA-256 hashes of its DER encoding, in addition to the usual verification of the certificate chain.
+// The hashes are in hex, and may be separated by colons, e.g. "AB:CD:...".
+// A connection to the server whose certificate matches none of the hashes fails with *CertPinError.
+func WithPinnedCertSHA256(hashes ...string) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		for _, hash := range hashes {
+			opts.PinnedCertSHA256 = append(opts.PinnedCertSHA256, normalizePin(hash))
+		}
+	})
+}
+
+// CertPinError is returned when the certificate of the server matches none of the pinned hashes.
+type CertPinError struct {
+	// ServerName is the server name of the connection.
+	ServerName string `json:"serverName"`
+	// SHA256 is the SHA-256 hash of the leaf certificate of the server in hex.
+	SHA256 string `json:"sha256"`
+}
+
+func (err *CertPinError) Error() string {
+	return fmt.Sprintf("certificate of %s is not pinned: sha256 %s", err.ServerName, err.SHA256)
+}
+
+func normalizePin(hash string) string {
+	return strings.ToLower(strings.ReplaceAll(hash, ":", ""))
+}
+
+// verifyPinnedCert returns a function for tls.Config.VerifyConnection verifying the leaf certificate by pins.
+func verifyPinnedCert(pins []string) func(cs tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return &CertPinError{ServerName: cs.ServerName}
+		}
+
+		sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+		hash := hex.EncodeToString(sum[:])
+		for _, pin := range pins {
+			if pin == hash {
+				return nil
+			}
+		}
+
+		return &CertPinError{
+			ServerName: cs.ServerName,
+			SHA256:     hash,
+		}
+	}
+}
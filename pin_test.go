@@ -0,0 +1,63 @@
+package jsonrpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCallWithPinnedCertSHA256(t *testing.T) {
+	server := httptest.NewTLSServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	pin := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	tests := map[string]struct {
+		pins    []string
+		wantErr bool
+	}{
+		"match": {
+			pins: []string{strings.Repeat("00", sha256.Size), pin},
+		},
+		"mismatch": {
+			pins:    []string{strings.Repeat("00", sha256.Size)},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(WithPinnedCertSHA256(tt.pins...))
+			client.HTTPClient.Transport.(*http.Transport).TLSClientConfig.RootCAs = roots
+
+			var result string
+			err := client.Call(context.Background(), server.URL, "test", nil, &result)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Client.Call() failed: %v", err)
+				}
+				return
+			}
+
+			var pinErr *CertPinError
+			if !errors.As(err, &pinErr) {
+				t.Fatalf("Client.Call() error got %v, want *CertPinError", err)
+			}
+			if pinErr.SHA256 != strings.ToLower(pin) {
+				t.Errorf("CertPinError.SHA256 got %s, want %s", pinErr.SHA256, strings.ToLower(pin))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+)
+
+// PipelineStep represents a step of a pipeline.
+type PipelineStep struct {
+	// Method is a method name to call.
+	Method string
+	// Params returns params of the call from the result of the previous step.
+	// prev is nil on the first step.
+	// The call is sent without params if Params is nil.
+	Params func(prev interface{}) (interface{}, error)
+	// Result is a value the result responded by the server is stored in.
+	Result interface{}
+}
+
+// Pipeline calls the methods of the steps on the url sequentially.
+// Each step receives the result of the previous step to build its params.
+// Pipeline stops at the first step that fails.
+//
+// Pipeline is not a JSON-RPC batch, every step is sent as a separate request.
+func (client *Client) Pipeline(ctx context.Context, url string, steps []PipelineStep, opts ...Option) error {
+	var prev interface{}
+	for i, step := range steps {
+		var params interface{}
+		if step.Params != nil {
+			p, err := step.Params(prev)
+			if err != nil {
+				return fmt.Errorf("failed to build params of step %d (%s): %w", i, step.Method, err)
+			}
+			params = p
+		}
+
+		if err := client.Call(ctx, url, step.Method, params, step.Result, opts...); err != nil {
+			return fmt.Errorf("step %d (%s) failed: %w", i, step.Method, err)
+		}
+
+		prev = step.Result
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testUser struct {
+	ID int `json:"id"`
+}
+
+type testPostsQuery struct {
+	UserID int `json:"userId"`
+}
+
+func TestClientPipeline(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		switch req.Method {
+		case "user.find":
+			return &testUser{ID: 42}, nil
+		case "user.posts":
+			var q testPostsQuery
+			if err := json.Unmarshal(req.Params, &q); err != nil {
+				return nil, &ResponseError{Code: InvalidParams, Message: err.Error()}
+			}
+			if q.UserID != 42 {
+				t.Errorf("user.posts got userId %d, want 42", q.UserID)
+			}
+			return []string{"first", "second"}, nil
+		}
+		return nil, &ResponseError{Code: MethodNotFound, Message: "method not found"}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var user testUser
+	var posts []string
+	err := client.Pipeline(context.Background(), server.URL, []PipelineStep{
+		{
+			Method: "user.find",
+			Params: func(prev interface{}) (interface{}, error) {
+				if prev != nil {
+					t.Errorf("first step got prev %v, want nil", prev)
+				}
+				return map[string]string{"email": "test@example.com"}, nil
+			},
+			Result: &user,
+		},
+		{
+			Method: "user.posts",
+			Params: func(prev interface{}) (interface{}, error) {
+				u := prev.(*testUser)
+				return &testPostsQuery{UserID: u.ID}, nil
+			},
+			Result: &posts,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Client.Pipeline() failed: %v", err)
+	}
+
+	if want := []string{"first", "second"}; !reflect.DeepEqual(posts, want) {
+		t.Errorf("Client.Pipeline() posts got %v, want %v", posts, want)
+	}
+}
+
+func TestClientPipelineShortCircuit(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		calls++
+		return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var first, second interface{}
+	err := client.Pipeline(context.Background(), server.URL, []PipelineStep{
+		{Method: "first", Result: &first},
+		{Method: "second", Result: &second},
+	})
+
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Client.Pipeline() error got %v, want *ResponseError", err)
+	}
+	if calls != 1 {
+		t.Errorf("server got %d calls, want 1", calls)
+	}
+}
@@ -0,0 +1,111 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// ProbeStatus is a classification of an endpoint by Probe.
+type ProbeStatus int
+
+const (
+	// ProbeUnreachable means that the endpoint cannot be reached over HTTP.
+	ProbeUnreachable ProbeStatus = iota + 1
+	// ProbeNotJSONRPC means that the endpoint speaks HTTP but not JSON-RPC 2.0.
+	ProbeNotJSONRPC
+	// ProbeJSONRPC means that the endpoint speaks JSON-RPC 2.0.
+	ProbeJSONRPC
+)
+
+func (status ProbeStatus) String() string {
+	switch status {
+	case ProbeUnreachable:
+		return "Unreachable"
+	case ProbeNotJSONRPC:
+		return "NotJSONRPC"
+	case ProbeJSONRPC:
+		return "JSONRPC"
+	}
+
+	return "Unknown"
+}
+
+// ProbeResult is a result of Probe.
+type ProbeResult struct {
+	// Status is the classification of the endpoint.
+	Status ProbeStatus
+	// Error is the error responded by the endpoint speaking JSON-RPC 2.0.
+	Error *ResponseError
+	// Reason is the error why the endpoint is not classified as ProbeJSONRPC.
+	Reason error
+}
+
+type probeRequest struct {
+	JSONRPC string    `json:"jsonrpc"`
+	ID      uuid.UUID `json:"id"`
+}
+
+// Probe verifies that the endpoint of the url speaks JSON-RPC 2.0.
+// It sends a request without the method, which is an invalid request,
+// and classifies the endpoint by the response.
+// The endpoint speaks JSON-RPC 2.0 if it responds a JSON-RPC 2.0 error, whatever the HTTP status is.
+//
+// The returned error is not nil only if the probe request cannot be sent.
+func (client *Client) Probe(ctx context.Context, url string, opts ...Option) (ProbeResult, error) {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	callOpts := newCallOptions(opts)
+
+	body, err := json.Marshal(&probeRequest{
+		JSONRPC: Version,
+		ID:      uuid.New(),
+	})
+	if err != nil {
+		return ProbeResult{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	res, err := client.do(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		if isTransportError(err) {
+			return ProbeResult{
+				Status: ProbeUnreachable,
+				Reason: err,
+			}, nil
+		}
+		return ProbeResult{}, err
+	}
+	defer closeBody(res.Body)
+
+	var rpcRes response
+	if err := json.NewDecoder(res.Body).Decode(&rpcRes); err != nil {
+		return ProbeResult{
+			Status: ProbeNotJSONRPC,
+			Reason: fmt.Errorf("failed to decode response JSON (%s): %w", res.Status, err),
+		}, nil
+	}
+
+	if rpcRes.JSONRPC != Version || rpcRes.Error == nil {
+		return ProbeResult{
+			Status: ProbeNotJSONRPC,
+			Reason: fmt.Errorf("server does not respond a JSON-RPC %s error to an invalid request (%s)", Version, res.Status),
+		}, nil
+	}
+
+	return ProbeResult{
+		Status: ProbeJSONRPC,
+		Error:  rpcRes.Error,
+	}, nil
+}
+
+// isTransportError reports whether err occurred while sending the request or receiving the response.
+func isTransportError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,50 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientProbe(t *testing.T) {
+	jsonrpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`))
+	}))
+	defer jsonrpcServer.Close()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>hello</html>"))
+	}))
+	defer httpServer.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		url  string
+		want ProbeStatus
+	}{
+		"JSONRPC":     {jsonrpcServer.URL, ProbeJSONRPC},
+		"NotJSONRPC":  {httpServer.URL, ProbeNotJSONRPC},
+		"Unreachable": {"http://" + refusedAddr(t), ProbeUnreachable},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := client.Probe(context.Background(), tt.url)
+			if err != nil {
+				t.Fatalf("Client.Probe() failed: %v", err)
+			}
+			if result.Status != tt.want {
+				t.Errorf("Client.Probe() status got %v, want %v (reason: %v)", result.Status, tt.want, result.Reason)
+			}
+			if tt.want == ProbeJSONRPC && (result.Error == nil || result.Error.Code != InvalidRequest) {
+				t.Errorf("Client.Probe() error got %v, want InvalidRequest", result.Error)
+			}
+			if tt.want != ProbeJSONRPC && result.Reason == nil {
+				t.Error("Client.Probe() reason must not be nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package jsonrpc
+
+import (
+	"io"
+	"net/http"
+)
+
+// WithUploadProgress returns an Option that calls fn with the number of the bytes of the request body
+// consumed by the transport so far, and the total size of the body, as the request is sent.
+// totalBytes is -1 if the size is not known, e.g. the body is streamed by a ParamsEncoder.
+//
+// fn is called from the goroutine sending the request, so it must return quickly.
+// A retried call reports the progress of each attempt from zero.
+func WithUploadProgress(fn func(bytesSent, totalBytes int64)) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.UploadProgress = fn
+	})
+}
+
+// trackUpload wraps the body of req to report the progress to the callback of WithUploadProgress.
+func (opts *callOptions) trackUpload(req *http.Request) {
+	fn := opts.UploadProgress
+	if fn == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	total := req.ContentLength
+	if total == 0 {
+		total = -1
+	}
+
+	req.Body = &progressBody{rc: req.Body, total: total, fn: fn}
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			rc, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &progressBody{rc: rc, total: total, fn: fn}, nil
+		}
+	}
+}
+
+// progressBody reports the number of the bytes read from rc to fn.
+type progressBody struct {
+	rc    io.ReadCloser
+	sent  int64
+	total int64
+	fn    func(bytesSent, totalBytes int64)
+}
+
+func (body *progressBody) Read(p []byte) (int, error) {
+	n, err := body.rc.Read(p)
+	if n > 0 {
+		body.sent += int64(n)
+		body.fn(body.sent, body.total)
+	}
+	return n, err
+}
+
+func (body *progressBody) Close() error {
+	return body.rc.Close()
+}
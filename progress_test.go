@@ -0,0 +1,46 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCallWithUploadProgress(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	params := []string{strings.Repeat("x", 1<<20)}
+
+	var sent []int64
+	var total int64
+	var result string
+	err := client.Call(context.Background(), server.URL, "upload", params, &result,
+		WithUploadProgress(func(bytesSent, totalBytes int64) {
+			sent = append(sent, bytesSent)
+			total = totalBytes
+		}))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	if len(sent) < 2 {
+		t.Fatalf("progress is reported %d times, want at least 2", len(sent))
+	}
+	for i := 1; i < len(sent); i++ {
+		if sent[i] <= sent[i-1] {
+			t.Fatalf("bytesSent got %v, want increasing", sent)
+		}
+	}
+	if total < 1<<20 {
+		t.Errorf("totalBytes got %d, want at least %d", total, 1<<20)
+	}
+	if last := sent[len(sent)-1]; last != total {
+		t.Errorf("last bytesSent got %d, want %d", last, total)
+	}
+}
@@ -0,0 +1,113 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ProtocolErrorKind is a kind of ProtocolError.
+type ProtocolErrorKind int
+
+const (
+	// EmptyBody means that the server responds with an empty body.
+	EmptyBody ProtocolErrorKind = iota + 1
+	// NotificationResponded means that the server responds to a notification with a body.
+	NotificationResponded
+	// NullID means that the server responds a successful result with a null id.
+	NullID
+	// VersionMismatch means that the server responds with a JSON-RPC version other than 2.0,
+	// e.g. the server speaks JSON-RPC 1.0.
+	VersionMismatch
+	// UnknownID means that the server responds to a batch with an id not sent in the batch,
+	// e.g. by a bug of a proxy or a poisoned cache.
+	UnknownID
+	// DuplicateID means that the server responds to a batch with more than one response to the same id.
+	DuplicateID
+)
+
+func (kind ProtocolErrorKind) String() string {
+	switch kind {
+	case EmptyBody:
+		return "EmptyBody"
+	case NotificationResponded:
+		return "NotificationResponded"
+	case NullID:
+		return "NullID"
+	case VersionMismatch:
+		return "VersionMismatch"
+	case UnknownID:
+		return "UnknownID"
+	case DuplicateID:
+		return "DuplicateID"
+	}
+
+	return "Unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler, so that the kind is marshaled to JSON by its name.
+func (kind ProtocolErrorKind) MarshalText() ([]byte, error) {
+	return []byte(kind.String()), nil
+}
+
+// ProtocolError is returned when the response from the server violates the JSON-RPC protocol.
+type ProtocolError struct {
+	// Kind is the kind of the violation.
+	Kind ProtocolErrorKind `json:"kind"`
+	// Message describes the violation.
+	Message string `json:"message"`
+	// Version is the JSON-RPC version responded by the server for VersionMismatch.
+	// It is empty if the jsonrpc member is missing, as JSON-RPC 1.0 omits it.
+	Version string `json:"version,omitempty"`
+}
+
+func (err *ProtocolError) Error() string {
+	return "protocol error: " + err.Message
+}
+
+// checkEmptyBody returns a ProtocolError of EmptyBody if err is caused by the empty body,
+// otherwise returns nil.
+func checkEmptyBody(err error) error {
+	if errors.Is(err, io.EOF) {
+		return &ProtocolError{
+			Kind:    EmptyBody,
+			Message: "server responds with an empty body",
+		}
+	}
+
+	return nil
+}
+
+// checkVersion returns a ProtocolError of VersionMismatch if version is not the JSON-RPC version of the client.
+func checkVersion(version string) error {
+	if version == Version {
+		return nil
+	}
+
+	msg := fmt.Sprintf("server responds with JSON-RPC version %q, want %q", version, Version)
+	if version == "" {
+		msg = fmt.Sprintf("server responds without JSON-RPC version, want %q", Version)
+	}
+
+	return &ProtocolError{
+		Kind:    VersionMismatch,
+		Message: msg,
+		Version: version,
+	}
+}
+
+// unknownID returns a ProtocolError of UnknownID for the response with the id.
+func unknownID(id interface{}) error {
+	return &ProtocolError{
+		Kind:    UnknownID,
+		Message: fmt.Sprintf("server responds with id %v not sent in the batch", id),
+	}
+}
+
+// duplicateID returns a ProtocolError of DuplicateID for the response with the id.
+func duplicateID(id interface{}) error {
+	return &ProtocolError{
+		Kind:    DuplicateID,
+		Message: fmt.Sprintf("server responds more than once to id %v in the batch", id),
+	}
+}
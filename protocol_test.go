@@ -0,0 +1,129 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientCallEmptyBody(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Content-Length", "0")
+			return
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "empty", nil, &result)
+	var protoErr *ProtocolError
+	if !errors.As(err, &protoErr) {
+		t.Fatalf("Client.Call() error got %v, want *ProtocolError", err)
+	}
+	if protoErr.Kind != EmptyBody {
+		t.Errorf("ProtocolError.Kind got %v, want EmptyBody", protoErr.Kind)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+
+	err = client.Call(context.Background(), server.URL, "empty", nil, &result, WithRetry(1, nil), WithRetryOnProtocolError(EmptyBody))
+	if err != nil {
+		t.Fatalf("Client.Call() with WithRetryOnProtocolError failed: %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls, want 2", calls)
+	}
+}
+
+func TestClientCallWithStrictResponseID(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		wantKind ProtocolErrorKind
+		wantCode ErrorCode
+	}{
+		"null id on success": {
+			body:     `{"jsonrpc":"2.0","result":"ok","id":null}`,
+			wantKind: NullID,
+		},
+		"null id on parse error": {
+			body:     `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`,
+			wantCode: ParseError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			var result string
+			err := client.Call(context.Background(), server.URL, "test", nil, &result, WithStrictResponseID())
+
+			if tt.wantKind != 0 {
+				var perr *ProtocolError
+				if !errors.As(err, &perr) || perr.Kind != tt.wantKind {
+					t.Errorf("Client.Call() error got %v, want ProtocolError of %v", err, tt.wantKind)
+				}
+				return
+			}
+
+			var rpcErr *ResponseError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != tt.wantCode {
+				t.Errorf("Client.Call() error got %v, want ResponseError of %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestClientCallVersionMismatch(t *testing.T) {
+	tests := map[string]struct {
+		envelope string
+		version  string
+	}{
+		"1.0":     {envelope: `{"jsonrpc":"1.0","result":"ok","error":null,"id":%s}`, version: "1.0"},
+		"missing": {envelope: `{"result":"ok","error":null,"id":%s}`, version: ""},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req testRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, tt.envelope, req.ID)
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			var result string
+			err := client.Call(context.Background(), server.URL, "test", nil, &result)
+			var protoErr *ProtocolError
+			if !errors.As(err, &protoErr) {
+				t.Fatalf("Client.Call() error got %v, want *ProtocolError", err)
+			}
+			if protoErr.Kind != VersionMismatch || protoErr.Version != tt.version {
+				t.Errorf("ProtocolError got %v %q, want VersionMismatch %q", protoErr.Kind, protoErr.Version, tt.version)
+			}
+		})
+	}
+}
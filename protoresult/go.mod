@@ -0,0 +1,10 @@
+module github.com/kechako/go-jsonrpc/protoresult
+
+go 1.18
+
+require (
+	github.com/kechako/go-jsonrpc v0.0.0-20261015074244-a4ee8517c97c
+	google.golang.org/protobuf v1.34.1
+)
+
+require github.com/google/uuid v1.1.1 // indirect
@@ -0,0 +1,26 @@
+// Package protoresult decodes results of JSON-RPC calls into protocol buffer messages with protojson.
+//
+// It is provided as a separate module,
+// so that the users of jsonrpc who do not use protocol buffers do not depend on it.
+package protoresult
+
+import (
+	"encoding/json"
+
+	"github.com/kechako/go-jsonrpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// WithProtoResult returns an Option that decodes the result by protojson.Unmarshal
+// if the result implements proto.Message, e.g. to decode the well-known types such as
+// google.protobuf.Timestamp from their JSON representations.
+// Other results are decoded by encoding/json.
+func WithProtoResult() jsonrpc.Option {
+	return jsonrpc.WithResultUnmarshaler(func(raw json.RawMessage, result interface{}) error {
+		if m, ok := result.(proto.Message); ok {
+			return protojson.Unmarshal(raw, m)
+		}
+		return json.Unmarshal(raw, result)
+	})
+}
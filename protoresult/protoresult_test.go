@@ -0,0 +1,62 @@
+package protoresult
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kechako/go-jsonrpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func rpcServer(t *testing.T, result string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + result + `}`))
+	}))
+}
+
+func TestWithProtoResult(t *testing.T) {
+	server := rpcServer(t, `"2024-01-02T03:04:05.5Z"`)
+	defer server.Close()
+
+	client := &jsonrpc.Client{}
+
+	var ts timestamppb.Timestamp
+	if err := client.Call(context.Background(), server.URL, "now", nil, &ts, WithProtoResult()); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	want := time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC)
+	if got := ts.AsTime(); !got.Equal(want) {
+		t.Errorf("Client.Call() got %v, want %v", got, want)
+	}
+
+	var plain timestamppb.Timestamp
+	if err := client.Call(context.Background(), server.URL, "now", nil, &plain); err == nil {
+		t.Errorf("Client.Call() without WithProtoResult got %v, want error", plain.AsTime())
+	}
+}
+
+func TestWithProtoResultNonProto(t *testing.T) {
+	server := rpcServer(t, `{"name":"jsonrpc"}`)
+	defer server.Close()
+
+	client := &jsonrpc.Client{}
+
+	var result map[string]string
+	if err := client.Call(context.Background(), server.URL, "info", nil, &result, WithProtoResult()); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if result["name"] != "jsonrpc" {
+		t.Errorf("Client.Call() got %v, want name jsonrpc", result)
+	}
+}
@@ -0,0 +1,40 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithProxyURL returns a ClientOption that sends the requests through the HTTP proxy of proxyURL,
+// instead of the proxy given by the environment variables.
+// It is not applied if the HTTPClient of the Client is replaced.
+func WithProxyURL(proxyURL *url.URL) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.ProxyURL = proxyURL
+	})
+}
+
+// WithProxyAuth returns a ClientOption that authenticates to the proxy given by WithProxyURL
+// with the basic authentication of user and pass, sent in the Proxy-Authorization header.
+// It overrides the user information of the proxy URL.
+func WithProxyAuth(user, pass string) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.ProxyUser = url.UserPassword(user, pass)
+	})
+}
+
+// proxy returns the Proxy function of the transport, or nil if no proxy is given.
+// The transport sends the user information of the proxy URL in the Proxy-Authorization header,
+// both to the requests forwarded and the CONNECT requests tunneling TLS.
+func (opts *clientOptions) proxy() func(*http.Request) (*url.URL, error) {
+	if opts.ProxyURL == nil {
+		return nil
+	}
+
+	proxyURL := *opts.ProxyURL
+	if opts.ProxyUser != nil {
+		proxyURL.User = opts.ProxyUser
+	}
+
+	return http.ProxyURL(&proxyURL)
+}
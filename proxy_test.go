@@ -0,0 +1,76 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewClientWithProxy(t *testing.T) {
+	const target = "http://rpc.example.test/rpc"
+
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "proxied", nil
+	})
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.String() != target {
+			t.Errorf("proxy got request to %s, want %s", r.URL, target)
+		}
+		if r.Header.Get("Proxy-Authorization") != wantAuth {
+			w.Header().Set("Proxy-Authenticate", `Basic realm="proxy"`)
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		opts       []ClientOption
+		wantStatus int
+	}{
+		"auth": {
+			opts: []ClientOption{WithProxyURL(proxyURL), WithProxyAuth("user", "secret")},
+		},
+		"wrong auth": {
+			opts:       []ClientOption{WithProxyURL(proxyURL), WithProxyAuth("user", "wrong")},
+			wantStatus: http.StatusProxyAuthRequired,
+		},
+		"no auth": {
+			opts:       []ClientOption{WithProxyURL(proxyURL)},
+			wantStatus: http.StatusProxyAuthRequired,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(tt.opts...)
+
+			var result string
+			err := client.Call(context.Background(), target, "proxy", nil, &result)
+			if tt.wantStatus != 0 {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+					t.Errorf("Client.Call() error got %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if result != "proxied" {
+				t.Errorf("Client.Call() got %q, want %q", result, "proxied")
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+)
+
+// RateLimiter limits the rate of requests.
+// *rate.Limiter of golang.org/x/time/rate implements RateLimiter.
+type RateLimiter interface {
+	// WaitN blocks until n tokens are available or ctx is done.
+	WaitN(ctx context.Context, n int) error
+}
+
+// WithRateLimiter returns an Option that waits for a token of limiter before sending each HTTP request.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.RateLimiter = limiter
+	})
+}
+
+// WithBatchRateLimiting returns an Option that makes CallBatch wait for a token per request in the batch,
+// instead of a token per HTTP request.
+// It is for quotas counting RPC operations, it has no effect without WithRateLimiter.
+func WithBatchRateLimiting() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.BatchRateLimiting = true
+	})
+}
+
+func (opts *callOptions) waitRateLimit(ctx context.Context) error {
+	if opts.RateLimiter == nil {
+		return nil
+	}
+
+	n := 1
+	if opts.BatchRateLimiting && opts.batchSize > 0 {
+		n = opts.batchSize
+	}
+
+	if err := opts.RateLimiter.WaitN(ctx, n); err != nil {
+		return fmt.Errorf("failed to wait for rate limiter: %w", err)
+	}
+
+	return nil
+}
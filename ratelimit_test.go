@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testRateLimiter struct {
+	waits []int
+}
+
+func (l *testRateLimiter) WaitN(ctx context.Context, n int) error {
+	l.waits = append(l.waits, n)
+	return nil
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	reqs := make([]BatchRequest, 50)
+	for i := range reqs {
+		reqs[i] = BatchRequest{Method: "op"}
+	}
+
+	tests := map[string]struct {
+		opts []Option
+		want []int
+	}{
+		"PerRequest": {nil, []int{1, 1}},
+		"PerElement": {[]Option{WithBatchRateLimiting()}, []int{1, 50}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiter := &testRateLimiter{}
+			opts := append([]Option{WithRateLimiter(limiter)}, tt.opts...)
+
+			var result string
+			if err := client.Call(context.Background(), server.URL, "op", nil, &result, opts...); err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if _, err := client.CallBatch(context.Background(), server.URL, reqs, opts...); err != nil {
+				t.Fatalf("Client.CallBatch() failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(limiter.waits, tt.want) {
+				t.Errorf("limiter waited for %v tokens, want %v", limiter.waits, tt.want)
+			}
+		})
+	}
+}
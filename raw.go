@@ -0,0 +1,116 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// CallWithRawResponse calls the method on the url with the params,
+// and returns the HTTP response as is for advanced inspection,
+// e.g. the TLS connection state, the protocol version and all headers.
+// The response is returned whatever its status is, and the body is not decoded.
+//
+// The body of the response is already read and buffered,
+// so it can be read once without the connection.
+// The body must not be consumed twice, a second read returns io.EOF.
+// The caller must call the returned cleanup function after using the response.
+func (client *Client) CallWithRawResponse(ctx context.Context, url string, method string, params interface{}, opts ...Option) (*http.Response, func() error, error) {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	if method == "" {
+		return nil, nil, errors.New("method is empty")
+	}
+
+	callOpts := newCallOptions(opts)
+	callOpts.method = method
+
+	_, body, err := requestBody(method, params, callOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := client.do(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		return nil, nil, contextError(ctx, err)
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	closeBody(res.Body)
+	if err != nil {
+		return nil, nil, contextError(ctx, fmt.Errorf("failed to read response body: %w", err))
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return res, res.Body.Close, nil
+}
+
+// Response is a JSON-RPC response object with its members left undecoded.
+type Response struct {
+	// JSONRPC is the JSON-RPC version responded by the server.
+	JSONRPC string `json:"jsonrpc"`
+	// Result is the raw result, empty if the server responds an error.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the error responded by the server, nil if the call succeeded.
+	Error *ResponseError `json:"error,omitempty"`
+	// ID is the raw id of the response.
+	ID json.RawMessage `json:"id"`
+}
+
+// CallEnvelope calls the method on the url with the params,
+// and returns the whole response without decoding the result.
+// The error responded by the server is returned in the Error of the response instead of the error,
+// which is returned only if the call fails without a response, e.g. by a transport error.
+//
+// The response is empty if the server accepts the request without a body, e.g. by 202 Accepted,
+// or the result is served from the cache of WithResultCache.
+func (client *Client) CallEnvelope(ctx context.Context, url string, method string, params interface{}, opts ...Option) (*Response, error) {
+	var res Response
+	err := client.Call(ctx, url, method, params, discardResult{}, append(opts[:len(opts):len(opts)], withEnvelope(&res))...)
+	if err != nil {
+		var rpcErr *ResponseError
+		if res.Error == nil || !errors.As(err, &rpcErr) {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+// withEnvelope returns an Option that stores the whole response into res.
+func withEnvelope(res *Response) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.envelope = res
+	})
+}
+
+// recordEnvelope stores the copy of the response rpcRes with the decoded error into the envelope of CallEnvelope.
+func (opts *callOptions) recordEnvelope(rpcRes *rawErrorResponse, rpcErr *ResponseError) {
+	if opts.envelope == nil {
+		return
+	}
+
+	res := Response{
+		JSONRPC: rpcRes.JSONRPC,
+		ID:      append(json.RawMessage(nil), rpcRes.ID...),
+	}
+	if rpcErr != nil {
+		e := *rpcErr
+		res.Error = &e
+	} else {
+		res.Result = append(json.RawMessage(nil), rpcRes.Result...)
+	}
+	*opts.envelope = res
+}
+
+// discardResult is a ResultScanner discarding the result.
+type discardResult struct{}
+
+func (discardResult) ScanRPC(data json.RawMessage) error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCallWithRawResponse(t *testing.T) {
+	server := httptest.NewUnstartedServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "raw", nil
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := &Client{
+		HTTPClient: server.Client(),
+	}
+
+	res, cleanup, err := client.CallWithRawResponse(context.Background(), server.URL, "raw", nil)
+	if err != nil {
+		t.Fatalf("Client.CallWithRawResponse() failed: %v", err)
+	}
+	defer cleanup()
+
+	if res.TLS == nil {
+		t.Error("response must have the TLS connection state")
+	}
+	if res.Proto != "HTTP/2.0" {
+		t.Errorf("response protocol got %s, want HTTP/2.0", res.Proto)
+	}
+	if got := res.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("response Content-Type got %q, want application/json", got)
+	}
+
+	var rpcRes testResponse
+	if err := json.NewDecoder(res.Body).Decode(&rpcRes); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if rpcRes.Result != "raw" {
+		t.Errorf("response result got %v, want raw", rpcRes.Result)
+	}
+}
+
+func TestClientCallEnvelope(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "fail" {
+			return nil, &ResponseError{Code: InvalidParams, Message: "invalid params"}
+		}
+		return map[string]int{"answer": 42}, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	res, err := client.CallEnvelope(context.Background(), server.URL, "answer", nil)
+	if err != nil {
+		t.Fatalf("Client.CallEnvelope() failed: %v", err)
+	}
+	if res.JSONRPC != Version {
+		t.Errorf("Response.JSONRPC got %q, want %q", res.JSONRPC, Version)
+	}
+	var id string
+	if err := json.Unmarshal(res.ID, &id); err != nil || id == "" {
+		t.Errorf("Response.ID got %s, want the request id", res.ID)
+	}
+	if string(res.Result) != `{"answer":42}` {
+		t.Errorf("Response.Result got %s, want %s", res.Result, `{"answer":42}`)
+	}
+	if res.Error != nil {
+		t.Errorf("Response.Error got %v, want nil", res.Error)
+	}
+
+	res, err = client.CallEnvelope(context.Background(), server.URL, "fail", nil)
+	if err != nil {
+		t.Fatalf("Client.CallEnvelope() failed: %v", err)
+	}
+	if res.Error == nil || res.Error.Code != InvalidParams {
+		t.Errorf("Response.Error got %v, want InvalidParams", res.Error)
+	}
+	if res.JSONRPC != Version || len(res.ID) == 0 || res.Result != nil {
+		t.Errorf("Client.CallEnvelope() got %+v, want the error response", res)
+	}
+}
@@ -0,0 +1,50 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownTag is returned when a call is made with a tag not registered to the ClientRegistry.
+var ErrUnknownTag = errors.New("unknown client tag")
+
+// ClientRegistry maps tags to the Clients configured differently, e.g. "fast" and "bulk" with their own transports,
+// so that the client of each call is selected by the tag in a single place.
+// The zero value is an empty registry ready to use. It is safe for concurrent use.
+type ClientRegistry struct {
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// Register registers the client with the tag, replacing the client already registered with it.
+func (registry *ClientRegistry) Register(tag string, client *Client) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.clients == nil {
+		registry.clients = make(map[string]*Client)
+	}
+	registry.clients[tag] = client
+}
+
+// Client returns the client registered with the tag, and reports whether it is registered.
+func (registry *ClientRegistry) Client(tag string) (*Client, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	client, ok := registry.clients[tag]
+	return client, ok
+}
+
+// RegistryCall calls the method on the url by the client registered with the tag like Client.Call.
+// It returns an error wrapping ErrUnknownTag if no client is registered with the tag.
+func (registry *ClientRegistry) RegistryCall(ctx context.Context, tag string, url string, method string, params interface{}, result interface{}, opts ...Option) error {
+	client, ok := registry.Client(tag)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownTag, tag)
+	}
+
+	return client.Call(ctx, url, method, params, result, opts...)
+}
@@ -0,0 +1,49 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// tagTransport is a RoundTripper recording the requests it sends.
+type tagTransport struct {
+	requests int
+}
+
+func (transport *tagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientRegistryRegistryCall(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	fast := &tagTransport{}
+	bulk := &tagTransport{}
+
+	var registry ClientRegistry
+	registry.Register("fast", &Client{HTTPClient: &http.Client{Transport: fast}})
+	registry.Register("bulk", &Client{HTTPClient: &http.Client{Transport: bulk}})
+
+	var result string
+	if err := registry.RegistryCall(context.Background(), "bulk", server.URL, "echo", nil, &result); err != nil {
+		t.Fatalf("ClientRegistry.RegistryCall() failed: %v", err)
+	}
+	if result != "ok" {
+		t.Errorf("ClientRegistry.RegistryCall() got %q, want %q", result, "ok")
+	}
+	if fast.requests != 0 || bulk.requests != 1 {
+		t.Errorf("ClientRegistry.RegistryCall() sent %d requests by fast and %d by bulk, want 0 and 1", fast.requests, bulk.requests)
+	}
+
+	err := registry.RegistryCall(context.Background(), "slow", server.URL, "echo", nil, &result)
+	if !errors.Is(err, ErrUnknownTag) {
+		t.Errorf("ClientRegistry.RegistryCall() error got %v, want %v", err, ErrUnknownTag)
+	}
+}
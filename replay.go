@@ -0,0 +1,149 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ReplayEntry is a line of the replay log written by WithReplayLog.
+type ReplayEntry struct {
+	// URL is the url the request is sent to.
+	URL string `json:"url"`
+	// Request is the request body, a request object or a batch array.
+	Request json.RawMessage `json:"request"`
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"status"`
+	// Response is the response body, or null if the body is empty or not a JSON.
+	Response json.RawMessage `json:"response"`
+}
+
+type replayLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WithReplayLog returns an Option that writes each HTTP request of the call and its response
+// to w as a line of ReplayEntry in JSON, which can be re-issued by Replay.
+// The entry is written when the response body is closed.
+//
+// The requests with the params streamed by a ParamsEncoder, and the batches sent by WithGETBatch are not logged.
+// The writes are serialized among the calls given the same Option.
+// Errors writing to w are ignored.
+func WithReplayLog(w io.Writer) Option {
+	log := &replayLog{w: w}
+	return optionFunc(func(opts *callOptions) {
+		opts.ReplayLog = log
+	})
+}
+
+// wrap returns the body of res recording the response of req into the log.
+func (log *replayLog) wrap(req *http.Request, res *http.Response) io.ReadCloser {
+	body, ok := requestBodyBytes(req)
+	if !ok {
+		return res.Body
+	}
+
+	return &replayBody{
+		rc:  res.Body,
+		log: log,
+		entry: ReplayEntry{
+			URL:        req.URL.String(),
+			Request:    body,
+			StatusCode: res.StatusCode,
+		},
+	}
+}
+
+func (log *replayLog) write(entry *ReplayEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	log.w.Write(b)
+}
+
+// replayBody buffers the response body read through it, and writes the entry to the log on Close.
+type replayBody struct {
+	rc    io.ReadCloser
+	log   *replayLog
+	entry ReplayEntry
+	buf   bytes.Buffer
+	once  sync.Once
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *replayBody) Close() error {
+	b.once.Do(func() {
+		if res := bytes.TrimSpace(b.buf.Bytes()); json.Valid(res) {
+			b.entry.Response = res
+		}
+		b.log.write(&b.entry)
+	})
+
+	return b.rc.Close()
+}
+
+// Replay re-issues the requests logged by WithReplayLog read from r with the client, in the order they are logged.
+// The request bodies are sent as is, so the request IDs are the same as the logged ones.
+// The responses are discarded, and Replay stops at the first request failed to be sent or not responded with 2xx.
+func Replay(ctx context.Context, r io.Reader, client *Client, opts ...Option) error {
+	callOpts := newCallOptions(opts)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry ReplayEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("failed to decode replay log at line %d: %w", line, err)
+		}
+
+		if err := client.replay(ctx, &entry, callOpts); err != nil {
+			return fmt.Errorf("failed to replay line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read replay log: %w", err)
+	}
+
+	return nil
+}
+
+func (client *Client) replay(ctx context.Context, entry *ReplayEntry, callOpts callOptions) error {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	res, err := client.do(ctx, entry.URL, bytes.NewReader(entry.Request), callOpts)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	closeBody(res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return &StatusError{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return req.Method + " done", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		buf.ReadFrom(r.Body)
+		mu.Lock()
+		bodies = append(bodies, buf.String())
+		mu.Unlock()
+		r.Body = ioutil.NopCloser(&buf)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var log bytes.Buffer
+	opt := WithReplayLog(&log)
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "first", nil, &result, opt); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if err := client.Call(context.Background(), server.URL, "second", []int{1, 2}, &result, opt); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("replay log got %d lines, want 2", len(lines))
+	}
+	for i, method := range []string{"first", "second"} {
+		var entry ReplayEntry
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("failed to decode replay log line %d: %v", i, err)
+		}
+		if entry.URL != server.URL || entry.StatusCode != http.StatusOK {
+			t.Errorf("line %d: ReplayEntry got %s %d, want %s 200", i, entry.URL, entry.StatusCode, server.URL)
+		}
+		if string(entry.Request) != bodies[i] {
+			t.Errorf("line %d: ReplayEntry.Request got %s, want %s", i, entry.Request, bodies[i])
+		}
+		var res testResponse
+		if err := json.Unmarshal(entry.Response, &res); err != nil || res.Result != method+" done" {
+			t.Errorf("line %d: ReplayEntry.Response got %s, want the result of %s", i, entry.Response, method)
+		}
+	}
+
+	recorded := bodies
+	bodies = nil
+
+	if err := Replay(context.Background(), strings.NewReader(log.String()), client); err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if !reflect.DeepEqual(bodies, recorded) {
+		t.Errorf("Replay() sent %q, want %q", bodies, recorded)
+	}
+}
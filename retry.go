@@ -0,0 +1,285 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// BackoffFunc returns the duration to wait before the retry.
+// attempt is the number of the retry, starting from 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ErrorBackoffFunc returns the duration to wait before the retry of the call failed with err.
+// attempt is the number of the retry, starting from 1.
+// It subsumes BackoffFunc to choose the backoff strategy by the error,
+// e.g. retrying immediately on a connection reset, and waiting longer on 503.
+type ErrorBackoffFunc func(attempt int, err error) time.Duration
+
+// errorAware returns the ErrorBackoffFunc ignoring the error, or nil if backoff is nil.
+func (backoff BackoffFunc) errorAware() ErrorBackoffFunc {
+	if backoff == nil {
+		return nil
+	}
+
+	return func(attempt int, err error) time.Duration {
+		return backoff(attempt)
+	}
+}
+
+// WithRetry returns an Option that retries the call up to maxRetries times
+// when it fails with a retryable error.
+// Transport errors and 502, 503 and 504 responses are retryable.
+// backoff returns the duration to wait before each retry, the call is retried immediately if backoff is nil.
+//
+// The request ID is not changed by retries.
+func WithRetry(maxRetries int, backoff BackoffFunc) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.MaxRetries = maxRetries
+		opts.Backoff = backoff.errorAware()
+	})
+}
+
+// WithRetryBackoff returns an Option that retries the call like WithRetry,
+// with the backoff which is given the error of the failed attempt.
+func WithRetryBackoff(maxRetries int, backoff ErrorBackoffFunc) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.MaxRetries = maxRetries
+		opts.Backoff = backoff
+	})
+}
+
+// WithRetrySafeOnly returns an Option that restricts the retries by WithRetry
+// to the errors which provably occurred before the server processed the request.
+// It enables retries of non-idempotent methods.
+//
+// The errors definitely not processed by the server are:
+//
+//   - failures to resolve the host name of the url
+//   - failures to establish a connection, e.g. connection refused
+//
+// Whether the server processed the request is unknown for all other errors,
+// e.g. timeouts while waiting for the response, and any HTTP status responded,
+// even 503 which may come from a proxy without contacting the server.
+// These errors are not retried.
+func WithRetrySafeOnly() Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.RetrySafeOnly = true
+	})
+}
+
+// WithFreshIDOnRetry returns an Option that retries the call by WithRetry with a newly generated request ID
+// instead of reusing the ID of the previous attempt.
+// An error responded with duplicateIDCode, which the server uses to reject a reused ID, becomes retryable.
+//
+// This conflicts with servers relying on the ID to deduplicate retried requests,
+// so it is only for methods which are safe to be processed more than once.
+func WithFreshIDOnRetry(duplicateIDCode ErrorCode) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.FreshIDOnRetry = true
+		opts.DuplicateIDCode = duplicateIDCode
+	})
+}
+
+// WithRetryOnProtocolError returns an Option that makes the ProtocolError of the kinds retryable by WithRetry.
+// e.g. EmptyBody responded by a proxy in some edge cases.
+func WithRetryOnProtocolError(kinds ...ProtocolErrorKind) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.RetryProtocolErrors = append(opts.RetryProtocolErrors, kinds...)
+	})
+}
+
+// WithRetryStatus returns an Option that makes the StatusError of the status codes retryable by WithRetry,
+// in addition to 502, 503 and 504.
+// They are retried even with WithRetrySafeOnly, so it is only for methods which are idempotent.
+func WithRetryStatus(codes ...int) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.RetryStatuses = append(opts.RetryStatuses, codes...)
+	})
+}
+
+// WithRetryOnErrorCode returns an Option that makes the ResponseError of the codes retryable by WithRetry,
+// e.g. an application error code the server responds when it rate limits the calls.
+// If the data of the error has a "retryAfter" field, the number of seconds,
+// the retry waits for it instead of the backoff.
+//
+// They are retried even with WithRetrySafeOnly, so the codes should be the ones responded
+// without processing the request.
+func WithRetryOnErrorCode(codes ...ErrorCode) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.RetryErrorCodes = append(opts.RetryErrorCodes, codes...)
+	})
+}
+
+// retry calls fn, and calls it again while it fails with a retryable error
+// up to the max retries of opts.
+// attempt is the number of the attempt, starting from 1.
+func (client *Client) retry(ctx context.Context, opts callOptions, fn func(attempt int) error) error {
+	err := fn(1)
+	for attempt := 1; ; attempt++ {
+		if err == nil || attempt > opts.MaxRetries || !opts.retryable(err) {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if !client.acquireRetry() {
+			return err
+		}
+
+		wait, ok := retryAfter(err)
+		if !ok && opts.Backoff != nil {
+			wait = opts.Backoff(attempt, err)
+		}
+		if sleepContext(ctx, wait) != nil {
+			client.releaseRetry()
+			return err
+		}
+
+		client.metrics.recordRetry()
+		err = fn(attempt + 1)
+		client.releaseRetry()
+	}
+}
+
+// WithGlobalRetryConcurrency returns a ClientOption that limits the retries in flight across the Client to n,
+// including the backoff before them.
+// A call failing while n retries are in flight returns the error without retrying,
+// so that the retries of all calls do not overwhelm the recovering server.
+func WithGlobalRetryConcurrency(n int) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.RetryConcurrency = n
+	})
+}
+
+// acquireRetry reserves a retry in flight, and reports whether it is reserved.
+func (client *Client) acquireRetry() bool {
+	if client.retrySem == nil {
+		return true
+	}
+
+	select {
+	case client.retrySem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseRetry releases a retry reserved by acquireRetry.
+func (client *Client) releaseRetry() {
+	if client.retrySem != nil {
+		<-client.retrySem
+	}
+}
+
+// sleepContext waits for d, or returns ctx.Err() if ctx is done before d elapses.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryable reports whether err is retryable.
+func (opts *callOptions) retryable(err error) bool {
+	if notProcessed(err) {
+		return true
+	}
+
+	var rpcErr *ResponseError
+	if errors.As(err, &rpcErr) {
+		if opts.FreshIDOnRetry && rpcErr.Code == opts.DuplicateIDCode {
+			return true
+		}
+		for _, code := range opts.RetryErrorCodes {
+			if rpcErr.Code == code {
+				return true
+			}
+		}
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		for _, code := range opts.RetryStatuses {
+			if statusErr.StatusCode == code {
+				return true
+			}
+		}
+	}
+
+	if opts.RetrySafeOnly {
+		return false
+	}
+
+	var protoErr *ProtocolError
+	if errors.As(err, &protoErr) {
+		for _, kind := range opts.RetryProtocolErrors {
+			if protoErr.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	if statusErr != nil {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	return isTransportError(err)
+}
+
+// retryAfter returns the duration of the "retryAfter" field in the data of the ResponseError,
+// and reports whether err has it.
+func retryAfter(err error) (time.Duration, bool) {
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) || rpcErr.Data == nil {
+		return 0, false
+	}
+
+	b, merr := json.Marshal(rpcErr.Data)
+	if merr != nil {
+		return 0, false
+	}
+	var data struct {
+		RetryAfter *float64 `json:"retryAfter"`
+	}
+	if json.Unmarshal(b, &data) != nil || data.RetryAfter == nil || *data.RetryAfter < 0 {
+		return 0, false
+	}
+
+	return time.Duration(*data.RetryAfter * float64(time.Second)), true
+}
+
+// notProcessed reports whether err definitely occurred before the server processed the request.
+func notProcessed(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return false
+}
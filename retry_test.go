@@ -0,0 +1,405 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// refusedAddr returns an address which refuses connections.
+func refusedAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	return addr
+}
+
+func TestClientCallRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var backoffs []int
+	backoff := func(attempt int) time.Duration {
+		backoffs = append(backoffs, attempt)
+		return time.Millisecond
+	}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "retry", nil, &result, WithRetry(2, backoff)); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls, want 2", calls)
+	}
+	if len(backoffs) != 1 || backoffs[0] != 1 {
+		t.Errorf("backoff got attempts %v, want [1]", backoffs)
+	}
+}
+
+func TestClientCallRetryBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		case 2:
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var waits []time.Duration
+	backoff := func(attempt int, err error) time.Duration {
+		wait := time.Duration(0)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusServiceUnavailable {
+			wait = 10 * time.Millisecond
+		}
+		waits = append(waits, wait)
+		return wait
+	}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "retry", nil, &result, WithRetryBackoff(2, backoff)); err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	want := []time.Duration{10 * time.Millisecond, 0}
+	if len(waits) != len(want) || waits[0] != want[0] || waits[1] != want[1] {
+		t.Errorf("backoff got %v, want %v", waits, want)
+	}
+}
+
+func TestClientCallRetrySafeOnlyConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	refused := refusedAddr(t)
+
+	var dials int32
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if atomic.AddInt32(&dials, 1) == 1 {
+				return dialer.DialContext(ctx, network, refused)
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	client := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "mutate", nil, &result, WithRetry(1, nil), WithRetrySafeOnly())
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+
+	if dials := atomic.LoadInt32(&dials); dials != 2 {
+		t.Errorf("transport got %d dials, want 2", dials)
+	}
+}
+
+func TestClientCallRetrySafeOnlyReadTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{
+		ResponseHeaderTimeout: 50 * time.Millisecond,
+	}
+	defer transport.CloseIdleConnections()
+
+	client := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "mutate", nil, &result, WithRetry(1, nil), WithRetrySafeOnly())
+	if err == nil {
+		t.Fatal("Client.Call() must fail with a read timeout")
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("server got %d calls, want 1", calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+
+	if err := client.Call(context.Background(), server.URL, "read", nil, &result, WithRetry(1, nil)); err != nil {
+		t.Fatalf("Client.Call() without WithRetrySafeOnly failed: %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls without WithRetrySafeOnly, want 2", calls)
+	}
+}
+
+func TestClientCallFreshIDOnRetry(t *testing.T) {
+	const duplicateID ErrorCode = -32099
+
+	var ids []string
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		ids = append(ids, string(req.ID))
+		if len(ids) == 1 {
+			return nil, &ResponseError{Code: duplicateID, Message: "duplicate id"}
+		}
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "mutate", nil, &result, WithRetry(1, nil))
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != duplicateID {
+		t.Fatalf("Client.Call() without WithFreshIDOnRetry error got %v, want duplicate id error", err)
+	}
+
+	ids = nil
+
+	err = client.Call(context.Background(), server.URL, "mutate", nil, &result, WithRetry(1, nil), WithFreshIDOnRetry(duplicateID))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("server got %d calls, want 2", len(ids))
+	}
+	if ids[0] == ids[1] {
+		t.Errorf("retry must use a fresh ID, got %s twice", ids[0])
+	}
+}
+
+func TestClientCallRetryStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+			return "ok", nil
+		}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "retry", nil, &result, WithRetry(1, nil))
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Client.Call() without WithRetryStatus error got %v, want 429 StatusError", err)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+
+	err = client.Call(context.Background(), server.URL, "retry", nil, &result, WithRetry(1, nil), WithRetrySafeOnly(), WithRetryStatus(http.StatusTooManyRequests))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls, want 2", calls)
+	}
+}
+
+func TestClientCallRetryOnErrorCode(t *testing.T) {
+	const rateLimited ErrorCode = -32005
+
+	var calls int32
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, &ResponseError{
+				Code:    rateLimited,
+				Message: "rate limited",
+				Data:    map[string]interface{}{"retryAfter": 0.05},
+			}
+		}
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the backoff is longer than the timeout, so the call succeeds only if retryAfter is honored.
+	backoff := func(attempt int) time.Duration { return time.Hour }
+
+	start := time.Now()
+	var result string
+	err := client.Call(ctx, server.URL, "limited", nil, &result, WithRetry(1, backoff), WithRetryOnErrorCode(rateLimited))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Client.Call() retried after %v, want at least 50ms", elapsed)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("server got %d calls, want 2", calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+
+	err = client.Call(ctx, server.URL, "limited", nil, &result, WithRetry(1, nil))
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rateLimited {
+		t.Errorf("Client.Call() without WithRetryOnErrorCode error got %v, want %d", err, rateLimited)
+	}
+}
+
+func TestClientCallGlobalRetryConcurrency(t *testing.T) {
+	const (
+		calls = 10
+		limit = 2
+	)
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var inflight, maxInflight int32
+	release := make(chan struct{})
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		var req testRequest
+		json.Unmarshal(body, &req)
+
+		mu.Lock()
+		retried := seen[string(req.ID)]
+		seen[string(req.ID)] = true
+		mu.Unlock()
+
+		if !retried {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		n := atomic.AddInt32(&inflight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInflight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&inflight, -1)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithGlobalRetryConcurrency(limit))
+
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		go func() {
+			var result string
+			errs <- client.Call(context.Background(), server.URL, "retry", nil, &result, WithRetry(1, nil))
+		}()
+	}
+
+	var succeeded, failed int
+	for i := 0; i < calls; i++ {
+		if i == calls-limit {
+			// the other calls have failed fast, so release the retries in flight.
+			close(release)
+		}
+
+		var statusErr *StatusError
+		switch err := <-errs; {
+		case err == nil:
+			succeeded++
+		case errors.As(err, &statusErr):
+			failed++
+		default:
+			t.Errorf("Client.Call() error got %v, want nil or *StatusError", err)
+		}
+	}
+
+	if max := atomic.LoadInt32(&maxInflight); max > limit {
+		t.Errorf("server got %d retries in flight, want at most %d", max, limit)
+	}
+	if succeeded != limit || failed != calls-limit {
+		t.Errorf("calls got %d succeeded and %d failed, want %d and %d", succeeded, failed, limit, calls-limit)
+	}
+}
+
+func BenchmarkClientCallRetry(b *testing.B) {
+	const duplicateID ErrorCode = -32099
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		res := &testResponse{JSONRPC: Version, ID: req.ID}
+		// fail the first two attempts of each call.
+		if atomic.AddInt32(&calls, 1)%3 != 0 {
+			res.Error = &ResponseError{Code: duplicateID, Message: "duplicate id", Data: map[string]interface{}{"id": req.ID}}
+		} else {
+			res.Result = "ok"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+	opts := []Option{WithRetry(2, nil), WithFreshIDOnRetry(duplicateID)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var result string
+		if err := client.Call(context.Background(), server.URL, "test", nil, &result, opts...); err != nil {
+			b.Fatalf("Client.Call() failed: %v", err)
+		}
+	}
+}
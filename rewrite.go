@@ -0,0 +1,73 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// MaxRewriteBytes is the maximum size of the response body read for the rewriter of WithResponseRewriter,
+// the envelope of WithResponseEnvelopePath and the verifier of WithResponseVerifier.
+const MaxRewriteBytes = 16 << 20
+
+// ErrRewriteTooLarge is returned when the response body is larger than MaxRewriteBytes
+// for the rewriter of WithResponseRewriter, the envelope of WithResponseEnvelopePath
+// or the verifier of WithResponseVerifier.
+var ErrRewriteTooLarge = errors.New("response body is too large to rewrite")
+
+// WithResponseRewriter returns an Option that calls rewrite with the raw response body,
+// and decodes the returned bytes instead of the body, e.g. to restore the ids rewritten by a proxy.
+// The response is rewritten before the id of the response is validated.
+// The body is the decompressed one if the response is compressed,
+// and verified by WithResponseVerifier before rewritten.
+//
+// The response body is read entirely up to MaxRewriteBytes, so results are not streamed with this option.
+// The call fails if the body is larger, or rewrite returns an error or invalid JSON.
+func WithResponseRewriter(rewrite func(raw []byte) ([]byte, error)) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ResponseRewriter = rewrite
+	})
+}
+
+// rewriteResponse reads the body of res, and replaces it with the one rewritten by the ResponseRewriter.
+func (opts *callOptions) rewriteResponse(res *http.Response) error {
+	if opts.ResponseRewriter == nil {
+		return nil
+	}
+
+	b, err := readRewriteBody(res)
+	if err != nil {
+		return err
+	}
+
+	b, err = opts.ResponseRewriter(b)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite response: %w", err)
+	}
+	if !json.Valid(b) {
+		return errors.New("failed to rewrite response: rewriter returns invalid JSON")
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return nil
+}
+
+// readRewriteBody reads the body of res entirely up to MaxRewriteBytes, and closes it.
+// The rest of the body is drained before closed, so that the trailer of res is received.
+func readRewriteBody(res *http.Response) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(res.Body, MaxRewriteBytes+1))
+	closeBody(res.Body)
+	res.Body = http.NoBody
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(b) > MaxRewriteBytes {
+		return nil, ErrRewriteTooLarge
+	}
+
+	return b, nil
+}
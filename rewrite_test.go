@@ -0,0 +1,66 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCallWithResponseRewriter(t *testing.T) {
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "rewritten", nil
+	})
+	// the proxy prefixes the id of the response.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		w.Write(bytes.Replace(rec.Body.Bytes(), []byte(`"id":"`), []byte(`"id":"proxy-`), 1))
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	if err := client.Call(context.Background(), server.URL, "echo", nil, &result); err == nil {
+		t.Fatalf("Client.Call() without the rewriter got %q, want error", result)
+	}
+
+	tests := map[string]struct {
+		rewrite func(raw []byte) ([]byte, error)
+		wantErr string
+	}{
+		"restore id": {
+			rewrite: func(raw []byte) ([]byte, error) {
+				return bytes.Replace(raw, []byte(`"proxy-`), []byte(`"`), 1), nil
+			},
+		},
+		"invalid JSON": {
+			rewrite: func(raw []byte) ([]byte, error) {
+				return raw[:len(raw)/2], nil
+			},
+			wantErr: "invalid JSON",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			err := client.Call(context.Background(), server.URL, "echo", nil, &result, WithResponseRewriter(tt.rewrite))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Client.Call() error got %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if result != "rewritten" {
+				t.Errorf("Client.Call() got %q, want %q", result, "rewritten")
+			}
+		})
+	}
+}
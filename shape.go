@@ -0,0 +1,71 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ObjectOrArray is a result which may be either a JSON object or a JSON array,
+// for methods returning an object normally but an array in some cases.
+// It captures whichever shape arrived, and decodes it by AsObject or AsArray.
+type ObjectOrArray struct {
+	raw json.RawMessage
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts a JSON object, a JSON array or null.
+func (v *ObjectOrArray) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || (b[0] != '{' && b[0] != '[' && !isNullJSON(b)) {
+		return fmt.Errorf("result is neither an object nor an array: %s", b)
+	}
+
+	if isNullJSON(b) {
+		v.raw = nil
+		return nil
+	}
+
+	v.raw = append(v.raw[:0], b...)
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v ObjectOrArray) MarshalJSON() ([]byte, error) {
+	if v.raw == nil {
+		return []byte("null"), nil
+	}
+	return v.raw, nil
+}
+
+// IsObject reports whether the result is a JSON object.
+func (v *ObjectOrArray) IsObject() bool {
+	return len(v.raw) > 0 && v.raw[0] == '{'
+}
+
+// IsArray reports whether the result is a JSON array.
+func (v *ObjectOrArray) IsArray() bool {
+	return len(v.raw) > 0 && v.raw[0] == '['
+}
+
+// Raw returns the raw JSON of the result, or nil if the result is null.
+func (v *ObjectOrArray) Raw() json.RawMessage {
+	return v.raw
+}
+
+// AsObject decodes the result into out if the result is a JSON object.
+func (v *ObjectOrArray) AsObject(out interface{}) error {
+	if !v.IsObject() {
+		return errors.New("result is not an object")
+	}
+	return json.Unmarshal(v.raw, out)
+}
+
+// AsArray decodes the result into out if the result is a JSON array.
+func (v *ObjectOrArray) AsArray(out interface{}) error {
+	if !v.IsArray() {
+		return errors.New("result is not an array")
+	}
+	return json.Unmarshal(v.raw, out)
+}
@@ -0,0 +1,75 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientCallObjectOrArray(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "array" {
+			return []*testAutoUser{{ID: 1, Name: "first"}, {ID: 2, Name: "second"}}, nil
+		}
+		return &testAutoUser{ID: 1, Name: "first"}, nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	t.Run("object", func(t *testing.T) {
+		var result ObjectOrArray
+		if err := client.Call(context.Background(), server.URL, "object", nil, &result); err != nil {
+			t.Fatalf("Client.Call() failed: %v", err)
+		}
+
+		if !result.IsObject() || result.IsArray() {
+			t.Fatalf("ObjectOrArray got %s, want an object", result.Raw())
+		}
+
+		var user testAutoUser
+		if err := result.AsObject(&user); err != nil {
+			t.Fatalf("ObjectOrArray.AsObject() failed: %v", err)
+		}
+		if want := (testAutoUser{ID: 1, Name: "first"}); user != want {
+			t.Errorf("ObjectOrArray.AsObject() got %+v, want %+v", user, want)
+		}
+
+		var users []testAutoUser
+		if err := result.AsArray(&users); err == nil {
+			t.Error("ObjectOrArray.AsArray() must fail for an object")
+		}
+	})
+
+	t.Run("array", func(t *testing.T) {
+		var result ObjectOrArray
+		if err := client.Call(context.Background(), server.URL, "array", nil, &result); err != nil {
+			t.Fatalf("Client.Call() failed: %v", err)
+		}
+
+		if !result.IsArray() || result.IsObject() {
+			t.Fatalf("ObjectOrArray got %s, want an array", result.Raw())
+		}
+
+		var users []testAutoUser
+		if err := result.AsArray(&users); err != nil {
+			t.Fatalf("ObjectOrArray.AsArray() failed: %v", err)
+		}
+		if want := []testAutoUser{{ID: 1, Name: "first"}, {ID: 2, Name: "second"}}; !reflect.DeepEqual(users, want) {
+			t.Errorf("ObjectOrArray.AsArray() got %+v, want %+v", users, want)
+		}
+
+		var user testAutoUser
+		if err := result.AsObject(&user); err == nil {
+			t.Error("ObjectOrArray.AsObject() must fail for an array")
+		}
+	})
+}
+
+func TestObjectOrArrayUnmarshalJSONInvalid(t *testing.T) {
+	var v ObjectOrArray
+	if err := v.UnmarshalJSON([]byte(`"string"`)); err == nil {
+		t.Error("ObjectOrArray.UnmarshalJSON() must fail for a string")
+	}
+}
@@ -0,0 +1,83 @@
+package jsonrpc
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle tracks the in-flight calls of a Client to cancel them on Shutdown.
+type lifecycle struct {
+	mu       sync.Mutex
+	closing  chan struct{}
+	closed   bool
+	inflight sync.WaitGroup
+}
+
+// begin returns a context derived from ctx, which is also canceled when the client is shut down,
+// and a function to be called when the call finishes.
+// The returned context is already canceled if the client has been shut down.
+func (client *Client) begin(ctx context.Context) (context.Context, func()) {
+	l := &client.lifecycle
+
+	l.mu.Lock()
+	if l.closing == nil {
+		l.closing = make(chan struct{})
+	}
+	closing := l.closing
+	closed := l.closed
+	if !closed {
+		l.inflight.Add(1)
+	}
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	if closed {
+		cancel()
+		return ctx, func() {}
+	}
+
+	go func() {
+		select {
+		case <-closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		cancel()
+		l.inflight.Done()
+	}
+}
+
+// Shutdown cancels all in-flight calls of the client, and waits for them to finish.
+// If ctx is done before they finish, Shutdown returns the error of ctx.
+//
+// The calls canceled by Shutdown fail with a *ContextError wrapping context.Canceled,
+// and the calls started after Shutdown fail immediately with the same error.
+func (client *Client) Shutdown(ctx context.Context) error {
+	l := &client.lifecycle
+
+	l.mu.Lock()
+	if l.closing == nil {
+		l.closing = make(chan struct{})
+	}
+	if !l.closed {
+		l.closed = true
+		close(l.closing)
+	}
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,63 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientShutdown(t *testing.T) {
+	const n = 5
+
+	started := make(chan struct{}, n)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &Client{}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var result string
+			errs[i] = client.Call(context.Background(), server.URL, "block", nil, &result)
+		}(i)
+	}
+	for i := 0; i < n; i++ {
+		<-started
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatalf("Client.Shutdown() failed: %v", err)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		var ctxErr *ContextError
+		if !errors.As(err, &ctxErr) || !errors.Is(err, context.Canceled) {
+			t.Errorf("call %d: Client.Call() error got %v, want *ContextError wrapping context.Canceled", i, err)
+		}
+	}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "after", nil, &result)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Client.Call() after Shutdown error got %v, want context.Canceled", err)
+	}
+}
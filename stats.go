@@ -0,0 +1,120 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptrace"
+)
+
+// CallStats is statistics of a call.
+type CallStats struct {
+	// RequestBytes is the size of the request body written to the connection,
+	// zero if the request fails before it is sent, e.g. failing to connect.
+	RequestBytes int64
+	// ResponseBytes is the size of the response body decoded by the client.
+	// It is the decompressed size if the response is compressed.
+	ResponseBytes int64
+	// ResponseWireBytes is the size of the response body on the wire.
+	// It is the compressed size if the response is compressed.
+	ResponseWireBytes int64
+	// ConnReused reports whether the request is sent on a connection reused from the pool,
+	// rather than a newly dialed connection.
+	ConnReused bool
+	// ConnWasIdle reports whether the reused connection was idle in the pool.
+	ConnWasIdle bool
+	// FromCache reports whether the result is served from the cache given by WithResultCache
+	// without a network call.
+	FromCache bool
+	// Stale reports whether the result is an expired one served from the cache
+	// because the call failed, by WithServeStaleOnError.
+	Stale bool
+	// Warnings is the warnings responded in the member of the response given by WithWarningsField.
+	// Each element is an element of the member if it is an array, otherwise the member itself.
+	Warnings []json.RawMessage
+	// IDRaw is the id of the request exactly as it is sent on the wire, e.g. a quoted string.
+	// It is of the last attempt if the call is retried with WithFreshIDOnRetry,
+	// and nil if the result is served from the cache.
+	IDRaw json.RawMessage
+}
+
+func withStats(stats *CallStats) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.stats = stats
+	})
+}
+
+// recordIDRaw records the id of the request as it is sent on the wire into the stats and the hooks.
+func (opts *callOptions) recordIDRaw(id requestID) {
+	if opts.stats == nil && opts.idRaw == nil {
+		return
+	}
+
+	b, err := json.Marshal(id)
+	if err != nil {
+		return
+	}
+	if opts.stats != nil {
+		opts.stats.IDRaw = b
+	}
+	if opts.idRaw != nil {
+		*opts.idRaw = b
+	}
+}
+
+// CallWithStats calls the method on the url with the params like Call,
+// and returns the statistics of the call.
+// If the call is retried, the statistics are of the last attempt.
+func (client *Client) CallWithStats(ctx context.Context, url string, method string, params interface{}, result interface{}, opts ...Option) (CallStats, error) {
+	var stats CallStats
+	err := client.Call(ctx, url, method, params, result, append(opts[:len(opts):len(opts)], withStats(&stats))...)
+
+	return stats, err
+}
+
+// WithWarningsField returns an Option that extracts the non-fatal warnings responded
+// in the top-level member field of the response, e.g. "_warnings", into CallStats.Warnings returned by CallWithStats.
+// The result is decoded as usual, and the call does not fail by the warnings.
+func WithWarningsField(field string) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.WarningsField = field
+	})
+}
+
+// extractWarnings extracts the warnings from the raw response object into the stats.
+func (opts *callOptions) extractWarnings(raw json.RawMessage) error {
+	if opts.WarningsField == "" || opts.stats == nil {
+		return nil
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+
+	warnings, ok := members[opts.WarningsField]
+	if !ok || isNullJSON(warnings) {
+		return nil
+	}
+
+	if b := bytes.TrimSpace(warnings); b[0] == '[' {
+		if err := json.Unmarshal(b, &opts.stats.Warnings); err != nil {
+			return fmt.Errorf("failed to decode warnings: %w", err)
+		}
+		return nil
+	}
+	opts.stats.Warnings = []json.RawMessage{warnings}
+
+	return nil
+}
+
+// traceConn returns a context tracing the connection of the request into stats.
+func traceConn(ctx context.Context, stats *CallStats) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			stats.ConnReused = info.Reused
+			stats.ConnWasIdle = info.WasIdle
+		},
+	})
+}
@@ -0,0 +1,170 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCallWithStatsGzip(t *testing.T) {
+	result := strings.Repeat("compressible ", 1000)
+
+	var wire int
+	var plain int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Accept-Encoding got %q, want gzip", r.Header.Get("Accept-Encoding"))
+		}
+
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		body, _ := json.Marshal(&testResponse{
+			JSONRPC: Version,
+			Result:  result,
+			ID:      req.ID,
+		})
+		plain = len(body)
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		zw.Close()
+		wire = buf.Len()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var got string
+	stats, err := client.CallWithStats(context.Background(), server.URL, "gzip", nil, &got)
+	if err != nil {
+		t.Fatalf("Client.CallWithStats() failed: %v", err)
+	}
+	if got != result {
+		t.Errorf("Client.CallWithStats() result got %d bytes, want %d bytes", len(got), len(result))
+	}
+
+	if stats.RequestBytes == 0 {
+		t.Error("CallStats.RequestBytes must not be 0")
+	}
+	if stats.ResponseBytes != int64(plain) {
+		t.Errorf("CallStats.ResponseBytes got %d, want %d", stats.ResponseBytes, plain)
+	}
+	if stats.ResponseWireBytes != int64(wire) {
+		t.Errorf("CallStats.ResponseWireBytes got %d, want %d", stats.ResponseWireBytes, wire)
+	}
+
+	if _, recv := client.Transferred(); recv != int64(wire) {
+		t.Errorf("Client.Transferred() recv got %d, want %d", recv, wire)
+	}
+}
+
+func TestClientCallWithStatsConnReused(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{},
+		},
+	}
+
+	for i, want := range []bool{false, true} {
+		var result string
+		stats, err := client.CallWithStats(context.Background(), server.URL, "test", nil, &result)
+		if err != nil {
+			t.Fatalf("Client.CallWithStats() failed: %v", err)
+		}
+
+		if stats.ConnReused != want {
+			t.Errorf("call %d: CallStats.ConnReused got %v, want %v", i, stats.ConnReused, want)
+		}
+		if stats.ConnWasIdle != want {
+			t.Errorf("call %d: CallStats.ConnWasIdle got %v, want %v", i, stats.ConnWasIdle, want)
+		}
+	}
+}
+
+func TestClientCallWithStatsWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"ok","_warnings":["deprecated method",{"limit":90}]}`, req.ID)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	stats, err := client.CallWithStats(context.Background(), server.URL, "old", nil, &result, WithWarningsField("_warnings"))
+	if err != nil {
+		t.Fatalf("Client.CallWithStats() failed: %v", err)
+	}
+	if result != "ok" {
+		t.Errorf("Client.CallWithStats() result got %q, want %q", result, "ok")
+	}
+
+	want := []string{`"deprecated method"`, `{"limit":90}`}
+	if len(stats.Warnings) != len(want) {
+		t.Fatalf("CallStats.Warnings got %d warnings, want %d", len(stats.Warnings), len(want))
+	}
+	for i, w := range want {
+		if string(stats.Warnings[i]) != w {
+			t.Errorf("CallStats.Warnings[%d] got %s, want %s", i, stats.Warnings[i], w)
+		}
+	}
+
+	stats, err = client.CallWithStats(context.Background(), server.URL, "old", nil, &result)
+	if err != nil {
+		t.Fatalf("Client.CallWithStats() failed: %v", err)
+	}
+	if stats.Warnings != nil {
+		t.Errorf("CallStats.Warnings got %s without WithWarningsField, want nil", stats.Warnings)
+	}
+}
+
+func TestClientCallWithStatsIDRaw(t *testing.T) {
+	var sentID json.RawMessage
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		sentID = req.ID
+		return "ok", nil
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var hookID json.RawMessage
+	hook := WithCallHook(func(ctx context.Context, info CallInfo) {
+		hookID = info.IDRaw
+	})
+
+	var result string
+	stats, err := client.CallWithStats(context.Background(), server.URL, "id", nil, &result, hook)
+	if err != nil {
+		t.Fatalf("Client.CallWithStats() failed: %v", err)
+	}
+	if len(sentID) == 0 || !bytes.Equal(stats.IDRaw, sentID) {
+		t.Errorf("CallStats.IDRaw got %s, want %s", stats.IDRaw, sentID)
+	}
+	if !bytes.Equal(hookID, sentID) {
+		t.Errorf("CallInfo.IDRaw got %s, want %s", hookID, sentID)
+	}
+}
@@ -0,0 +1,307 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CallStream calls the method on the url with the params,
+// and calls fn with each element of the result array as soon as it is decoded from the response body,
+// without buffering the whole result.
+// CallStream stops reading the response if fn returns an error, and returns the error.
+//
+// The result must be an array. The call is not retried.
+func (client *Client) CallStream(ctx context.Context, url string, method string, params interface{}, fn func(item json.RawMessage) error, opts ...Option) error {
+	ctx, done := client.begin(ctx)
+	defer done()
+
+	if method == "" {
+		return errors.New("method is empty")
+	}
+
+	callOpts := newCallOptions(opts)
+	callOpts.method = method
+
+	var idRaw json.RawMessage
+	if len(callOpts.Hooks) > 0 {
+		callOpts.idRaw = &idRaw
+	}
+
+	id, body, err := requestBody(method, params, callOpts)
+	if err != nil {
+		return err
+	}
+	callOpts.recordIDRaw(id)
+
+	start := time.Now()
+	err = contextError(ctx, client.callStreamResult(ctx, url, id, body, fn, callOpts))
+	callOpts.runHooks(ctx, CallInfo{
+		Method:   method,
+		Duration: time.Since(start),
+		Err:      err,
+		IDRaw:    idRaw,
+	})
+
+	return err
+}
+
+func (client *Client) callStreamResult(ctx context.Context, url string, id requestID, body []byte, fn func(item json.RawMessage) error, callOpts callOptions) error {
+	res, err := client.post(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		return err
+	}
+	defer closeBody(res.Body)
+
+	if callOpts.ErrorTrailer == "" {
+		return client.streamResponse(res, id, fn, callOpts)
+	}
+
+	var fnErr error
+	err = client.streamResponse(res, id, func(item json.RawMessage) error {
+		fnErr = fn(item)
+		return fnErr
+	}, callOpts)
+	if fnErr != nil {
+		return err
+	}
+
+	return client.withTrailerError(res, err, callOpts)
+}
+
+// streamResponse reads the response of the request of the id, and calls fn with each element of the result array.
+// The response is validated like Call, but the version and the id are validated after the result is streamed.
+func (client *Client) streamResponse(res *http.Response, id requestID, fn func(item json.RawMessage) error, callOpts callOptions) error {
+	dec := json.NewDecoder(res.Body)
+	if err := expectDelim(dec, '{'); err != nil {
+		if perr := checkEmptyBody(err); perr != nil {
+			return perr
+		}
+		return err
+	}
+
+	var version string
+	var resID json.RawMessage
+	var rpcErr *ResponseError
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to decode response JSON: %w", err)
+		}
+		key, _ := tok.(string)
+
+		switch {
+		case strings.EqualFold(key, "result"):
+			if err := streamArray(dec, fn); err != nil {
+				return err
+			}
+		case strings.EqualFold(key, "error"):
+			if err := dec.Decode(&rpcErr); err != nil {
+				return fmt.Errorf("failed to decode response JSON: %w", err)
+			}
+		case strings.EqualFold(key, "jsonrpc"):
+			if err := dec.Decode(&version); err != nil {
+				return fmt.Errorf("failed to decode response JSON: %w", err)
+			}
+		case strings.EqualFold(key, "id"):
+			if err := dec.Decode(&resID); err != nil {
+				return fmt.Errorf("failed to decode response JSON: %w", err)
+			}
+		default:
+			var v json.RawMessage
+			if err := dec.Decode(&v); err != nil {
+				return fmt.Errorf("failed to decode response JSON: %w", err)
+			}
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	if err := checkVersion(version); err != nil {
+		return err
+	}
+	if rpcErr != nil {
+		callOpts.mapErrorCode(rpcErr)
+		client.decodeErrorData(rpcErr)
+		return rpcErr
+	}
+
+	return callOpts.checkResponseID(resID, id)
+}
+
+// streamArray calls fn with each element of the array read from dec.
+// A null result is treated as an empty array.
+func streamArray(dec *json.Decoder, fn func(item json.RawMessage) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("result is not an array: %v", tok)
+	}
+
+	items := 0
+	for dec.More() {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return incompleteResult(items, fmt.Errorf("failed to decode response JSON: %w", err))
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+		items++
+	}
+
+	return incompleteResult(items, expectDelim(dec, ']'))
+}
+
+// IncompleteResultError is returned by the streaming calls when the response ends in the middle of the result,
+// e.g. the server closes the connection, so that the items already processed are known to be truncated.
+type IncompleteResultError struct {
+	// Items is the number of the items processed before the response ends.
+	Items int
+	// Err is the error reading the response.
+	Err error
+}
+
+func (err *IncompleteResultError) Error() string {
+	return fmt.Sprintf("result is incomplete after %d items: %v", err.Items, err.Err)
+}
+
+func (err *IncompleteResultError) Unwrap() error {
+	return err.Err
+}
+
+// MarshalJSON implements json.Marshaler.
+// The underlying error is marshaled as its message.
+func (err *IncompleteResultError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Items int    `json:"items"`
+		Error string `json:"error"`
+	}{
+		Items: err.Items,
+		Error: errorMessage(err.Err),
+	})
+}
+
+// incompleteResult returns err as an IncompleteResultError if it is caused by the unexpected end of the response.
+func incompleteResult(items int, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &IncompleteResultError{
+			Items: items,
+			Err:   err,
+		}
+	}
+
+	return err
+}
+
+// expectDelim reads the next token from dec, and returns an error if it is not the delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+		return fmt.Errorf("failed to decode response JSON: %w", err)
+	}
+	if tok != delim {
+		return fmt.Errorf("failed to decode response JSON: unexpected token %v, want %v", tok, delim)
+	}
+
+	return nil
+}
+
+// StreamInterruptedError is returned by CallStreamResumable when the streaming call is interrupted.
+// The call can be resumed from Token, by calling again with the token in the params.
+type StreamInterruptedError struct {
+	// Token is the continuation token of the last item processed successfully.
+	// It is empty if no item is processed.
+	Token string
+	// Err is the error which interrupted the call.
+	Err error
+}
+
+func (err *StreamInterruptedError) Error() string {
+	return fmt.Sprintf("stream is interrupted after token %q: %v", err.Token, err.Err)
+}
+
+func (err *StreamInterruptedError) Unwrap() error {
+	return err.Err
+}
+
+// MarshalJSON implements json.Marshaler.
+// The underlying error is marshaled as its message.
+func (err *StreamInterruptedError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Token string `json:"token"`
+		Error string `json:"error"`
+	}{
+		Token: err.Token,
+		Error: errorMessage(err.Err),
+	})
+}
+
+// errorMessage returns the message of err, or an empty string if err is nil.
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// CallStreamResumable calls the method like CallStream,
+// and extracts the continuation token from each item by token after fn processes it successfully.
+// If the call is interrupted, e.g. by a broken connection in the middle of the result,
+// it returns a *StreamInterruptedError with the token of the last item processed successfully.
+// The other errors, e.g. a *ResponseError or the errors returned by fn and token, are returned as is,
+// since the call cannot be resumed from them.
+func (client *Client) CallStreamResumable(ctx context.Context, url string, method string, params interface{}, token func(item json.RawMessage) (string, error), fn func(item json.RawMessage) error, opts ...Option) error {
+	var last string
+	var itemErr error
+	err := client.CallStream(ctx, url, method, params, func(item json.RawMessage) error {
+		if err := fn(item); err != nil {
+			itemErr = err
+			return err
+		}
+
+		t, err := token(item)
+		if err != nil {
+			itemErr = fmt.Errorf("failed to extract continuation token: %w", err)
+			return itemErr
+		}
+		last = t
+
+		return nil
+	}, opts...)
+	if err != nil && itemErr == nil && interrupted(err) {
+		return &StreamInterruptedError{
+			Token: last,
+			Err:   err,
+		}
+	}
+
+	return err
+}
+
+// interrupted reports whether err is caused by the transport or the read of the response,
+// so that the streaming call can be resumed.
+func interrupted(err error) bool {
+	var incompleteErr *IncompleteResultError
+	var netErr net.Error
+	return isTransportError(err) || errors.As(err, &incompleteErr) || errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, ErrBodyReadTimeout)
+}
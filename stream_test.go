@@ -0,0 +1,320 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testStreamItem struct {
+	Token string `json:"token"`
+	Value int    `json:"value"`
+}
+
+// streamServer returns a server which streams items 1 to 5 after the token in params.
+// It breaks the response after the item of breakAfter if breakAfter is positive.
+func streamServer(t *testing.T, breakAfter int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		var params []string
+		json.Unmarshal(req.Params, &params)
+		start := 1
+		if len(params) > 0 {
+			n, _ := strconv.Atoi(params[0])
+			start = n + 1
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":[`, req.ID)
+		for i := start; i <= 5; i++ {
+			if i > start {
+				fmt.Fprint(w, ",")
+			}
+			b, _ := json.Marshal(&testStreamItem{Token: strconv.Itoa(i), Value: i * 10})
+			w.Write(b)
+			if i == breakAfter {
+				// the response is broken in the middle of the result.
+				fmt.Fprint(w, `,{"token":`)
+				return
+			}
+		}
+		fmt.Fprint(w, "]}")
+	}))
+}
+
+func TestClientCallStream(t *testing.T) {
+	server := streamServer(t, 0)
+	defer server.Close()
+
+	client := &Client{}
+
+	var values []int
+	err := client.CallStream(context.Background(), server.URL, "items", nil, func(item json.RawMessage) error {
+		var v testStreamItem
+		if err := json.Unmarshal(item, &v); err != nil {
+			return err
+		}
+		values = append(values, v.Value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Client.CallStream() failed: %v", err)
+	}
+
+	if want := []int{10, 20, 30, 40, 50}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Client.CallStream() got %v, want %v", values, want)
+	}
+}
+
+func TestClientCallStreamIncomplete(t *testing.T) {
+	server := streamServer(t, 3)
+	defer server.Close()
+
+	client := &Client{}
+
+	var items int
+	err := client.CallStream(context.Background(), server.URL, "items", nil, func(item json.RawMessage) error {
+		items++
+		return nil
+	})
+
+	var incompleteErr *IncompleteResultError
+	if !errors.As(err, &incompleteErr) {
+		t.Fatalf("Client.CallStream() error got %v, want *IncompleteResultError", err)
+	}
+	if incompleteErr.Items != 3 || items != 3 {
+		t.Errorf("IncompleteResultError.Items got %d with %d items processed, want 3", incompleteErr.Items, items)
+	}
+}
+
+func TestClientCallStreamError(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	err := client.CallStream(context.Background(), server.URL, "items", nil, func(item json.RawMessage) error {
+		t.Errorf("fn is called with %s", item)
+		return nil
+	})
+
+	var rpcErr *ResponseError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != InternalError {
+		t.Errorf("Client.CallStream() error got %v, want InternalError", err)
+	}
+}
+
+func TestClientCallStreamResumable(t *testing.T) {
+	token := func(item json.RawMessage) (string, error) {
+		var v testStreamItem
+		if err := json.Unmarshal(item, &v); err != nil {
+			return "", err
+		}
+		return v.Token, nil
+	}
+
+	client := &Client{}
+
+	var values []int
+	fn := func(item json.RawMessage) error {
+		var v testStreamItem
+		if err := json.Unmarshal(item, &v); err != nil {
+			return err
+		}
+		values = append(values, v.Value)
+		return nil
+	}
+
+	interrupted := streamServer(t, 2)
+	defer interrupted.Close()
+
+	err := client.CallStreamResumable(context.Background(), interrupted.URL, "items", nil, token, fn)
+
+	var serr *StreamInterruptedError
+	if !errors.As(err, &serr) {
+		t.Fatalf("Client.CallStreamResumable() error got %v, want *StreamInterruptedError", err)
+	}
+	if serr.Token != "2" {
+		t.Fatalf("StreamInterruptedError.Token got %q, want %q", serr.Token, "2")
+	}
+
+	resumed := streamServer(t, 0)
+	defer resumed.Close()
+
+	if err := client.CallStreamResumable(context.Background(), resumed.URL, "items", []string{serr.Token}, token, fn); err != nil {
+		t.Fatalf("Client.CallStreamResumable() failed to resume: %v", err)
+	}
+
+	if want := []int{10, 20, 30, 40, 50}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Client.CallStreamResumable() got %v, want %v", values, want)
+	}
+}
+
+func TestClientCallStreamResumableFinalError(t *testing.T) {
+	token := func(item json.RawMessage) (string, error) {
+		return "", nil
+	}
+
+	rpcServer := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return nil, &ResponseError{Code: InternalError, Message: "internal error"}
+	}))
+	defer rpcServer.Close()
+	itemsServer := streamServer(t, 0)
+	defer itemsServer.Close()
+
+	client := &Client{}
+	errFn := errors.New("fn error")
+
+	tests := map[string]struct {
+		url  string
+		fn   func(item json.RawMessage) error
+		want func(err error) bool
+	}{
+		"response error": {
+			url: rpcServer.URL,
+			fn: func(item json.RawMessage) error {
+				return nil
+			},
+			want: func(err error) bool {
+				var rpcErr *ResponseError
+				return errors.As(err, &rpcErr) && rpcErr.Code == InternalError
+			},
+		},
+		"fn error": {
+			url: itemsServer.URL,
+			fn: func(item json.RawMessage) error {
+				return errFn
+			},
+			want: func(err error) bool {
+				return errors.Is(err, errFn)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := client.CallStreamResumable(context.Background(), tt.url, "items", nil, token, tt.fn)
+
+			var serr *StreamInterruptedError
+			if errors.As(err, &serr) {
+				t.Errorf("Client.CallStreamResumable() error got %v, must not be *StreamInterruptedError", err)
+			}
+			if !tt.want(err) {
+				t.Errorf("Client.CallStreamResumable() error got %v", err)
+			}
+		})
+	}
+}
+
+func TestClientCallStreamValidation(t *testing.T) {
+	tests := map[string]struct {
+		response string
+		kind     ProtocolErrorKind
+	}{
+		"version": {
+			response: `{"jsonrpc":"1.0","id":%s,"result":[1]}`,
+			kind:     VersionMismatch,
+		},
+		"missing version": {
+			response: `{"id":%s,"result":[1]}`,
+			kind:     VersionMismatch,
+		},
+		"id": {
+			response: `{"jsonrpc":"2.0","id":7,"result":[1]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req testRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if strings.Contains(tt.response, "%s") {
+					fmt.Fprintf(w, tt.response, req.ID)
+					return
+				}
+				fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			err := client.CallStream(context.Background(), server.URL, "items", nil, func(item json.RawMessage) error {
+				return nil
+			})
+			if err == nil {
+				t.Fatal("Client.CallStream() must fail")
+			}
+			var protoErr *ProtocolError
+			if tt.kind != 0 && (!errors.As(err, &protoErr) || protoErr.Kind != tt.kind) {
+				t.Errorf("Client.CallStream() error got %v, want ProtocolError of %v", err, tt.kind)
+			}
+		})
+	}
+}
+
+func TestClientCallStreamErrorTrailer(t *testing.T) {
+	tests := map[string]struct {
+		body      string
+		wantItems int
+	}{
+		"partial body":  {body: `[1,2,{"item":`, wantItems: 2},
+		"complete body": {body: `[1,2,3]}`, wantItems: 0},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req testRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s`, req.ID, tt.body)
+				w.(http.Flusher).Flush()
+				// the server knows the failure only after streaming the result.
+				w.Header().Set(http.TrailerPrefix+DefaultErrorTrailer, `{"code":-32603,"message":"stream failed"}`)
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			err := client.CallStream(context.Background(), server.URL, "items", nil, func(item json.RawMessage) error {
+				return nil
+			}, WithErrorTrailer(DefaultErrorTrailer))
+
+			var rpcErr *ResponseError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != InternalError || rpcErr.Message != "stream failed" {
+				t.Fatalf("Client.CallStream() error got %v, want the error in the trailer", err)
+			}
+
+			var incompleteErr *IncompleteResultError
+			if errors.As(err, &incompleteErr) != (tt.wantItems > 0) {
+				t.Fatalf("Client.CallStream() error got %v, want incomplete %v", err, tt.wantItems > 0)
+			}
+			if incompleteErr != nil && incompleteErr.Items != tt.wantItems {
+				t.Errorf("IncompleteResultError.Items got %d, want %d", incompleteErr.Items, tt.wantItems)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"sync"
+)
+
+// WithMaxSubscriptions returns a ClientOption that limits the number of the pending subscriptions of the Client to n,
+// including the ones waiting for the response of the server,
+// so that a misbehaving server never holding the streams does not pile up the goroutines reading them.
+// Subscribe fails with *TooManyPendingError if the limit is reached.
+func WithMaxSubscriptions(n int) ClientOption {
+	return clientOptionFunc(func(opts *clientOptions) {
+		opts.MaxSubscriptions = n
+	})
+}
+
+// TooManyPendingError is returned by Subscribe when the number of the pending subscriptions
+// reaches the limit given by WithMaxSubscriptions.
+type TooManyPendingError struct {
+	// Pending is the number of the pending subscriptions.
+	Pending int `json:"pending"`
+	// Max is the limit of the pending subscriptions.
+	Max int `json:"max"`
+}
+
+func (err *TooManyPendingError) Error() string {
+	return fmt.Sprintf("too many pending subscriptions: %d of max %d", err.Pending, err.Max)
+}
+
+// StreamStats is the statistics of the subscriptions of a Client.
+type StreamStats struct {
+	// Pending is the number of the subscriptions not closed yet,
+	// including the ones waiting for the response of the server.
+	Pending int
+	// Readers is the number of the goroutines reading the streams of the subscriptions.
+	Readers int
+}
+
+// StreamStats returns the current statistics of the subscriptions of the Client.
+func (client *Client) StreamStats() StreamStats {
+	client.streams.mu.Lock()
+	defer client.streams.mu.Unlock()
+
+	return StreamStats{
+		Pending: client.streams.pending,
+		Readers: client.streams.readers,
+	}
+}
+
+// streamTracker tracks the subscriptions of a Client.
+type streamTracker struct {
+	max int
+
+	mu      sync.Mutex
+	pending int
+	readers int
+}
+
+// acquire reserves a pending subscription, or returns *TooManyPendingError if the limit is reached.
+func (t *streamTracker) acquire() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.max > 0 && t.pending >= t.max {
+		return &TooManyPendingError{
+			Pending: t.pending,
+			Max:     t.max,
+		}
+	}
+	t.pending++
+
+	return nil
+}
+
+// release releases a pending subscription reserved by acquire.
+func (t *streamTracker) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending--
+}
+
+// reading adds delta to the number of the readers.
+func (t *streamTracker) reading(delta int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.readers += delta
+}
@@ -0,0 +1,185 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Notification is a notification delivered by a Subscription.
+type Notification struct {
+	// Method is the method name of the notification.
+	Method string `json:"method"`
+	// Params is the raw params of the notification.
+	Params json.RawMessage `json:"params"`
+}
+
+// Subscription is a stream of the notifications subscribed by Subscribe.
+type Subscription struct {
+	// C delivers the notifications. It is closed when the stream ends.
+	C <-chan Notification
+
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+// Err returns the error terminated the stream after C is closed,
+// or nil if the stream is closed by the server or Close.
+func (sub *Subscription) Err() error {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	return sub.err
+}
+
+// Close stops the subscription. C is closed after Close is called.
+func (sub *Subscription) Close() {
+	sub.mu.Lock()
+	sub.closed = true
+	sub.mu.Unlock()
+
+	sub.cancel()
+}
+
+// Subscribe calls the method on the url with the params, and delivers the notifications streamed by the server
+// in the response of Server-Sent Events.
+//
+// The events are routed by their event types:
+//
+//   - "data", or no event type: the data is a JSON-RPC notification delivered on C
+//   - "error": the data is a JSON-RPC error object terminating the stream, returned by Err as *ResponseError
+//   - "close": the server closes the stream
+//
+// The events of other types are ignored.
+func (client *Client) Subscribe(ctx context.Context, url string, method string, params interface{}, opts ...Option) (*Subscription, error) {
+	if method == "" {
+		return nil, errors.New("method is empty")
+	}
+
+	callOpts := newCallOptions(opts)
+	callOpts.method = method
+
+	header := callOpts.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Accept", "text/event-stream")
+	callOpts.Header = header
+
+	_, body, err := requestBody(method, params, callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.streams.acquire(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	ctx, done := client.begin(ctx)
+
+	res, err := client.post(ctx, url, bytes.NewReader(body), callOpts)
+	if err != nil {
+		done()
+		cancel()
+		client.streams.release()
+		return nil, contextError(ctx, err)
+	}
+
+	c := make(chan Notification)
+	sub := &Subscription{
+		C:      c,
+		cancel: cancel,
+	}
+
+	client.streams.reading(1)
+	go func() {
+		defer close(c)
+		defer client.streams.release()
+		defer client.streams.reading(-1)
+		defer done()
+		// the body is closed without draining, since the server may keep the stream open after it ends.
+		defer res.Body.Close()
+		defer cancel()
+
+		err := readEvents(bufio.NewReader(res.Body), func(event, data string) (bool, error) {
+			switch event {
+			case "", "data":
+				var n Notification
+				if err := json.Unmarshal([]byte(data), &n); err != nil {
+					return false, fmt.Errorf("failed to decode notification JSON: %w", err)
+				}
+				select {
+				case c <- n:
+				case <-ctx.Done():
+					return false, ctx.Err()
+				}
+			case "error":
+				var rpcErr ResponseError
+				if err := json.Unmarshal([]byte(data), &rpcErr); err != nil {
+					return false, fmt.Errorf("failed to decode error JSON: %w", err)
+				}
+				callOpts.mapErrorCode(&rpcErr)
+				client.decodeErrorData(&rpcErr)
+				return false, &rpcErr
+			case "close":
+				return false, nil
+			}
+			return true, nil
+		})
+		sub.mu.Lock()
+		defer sub.mu.Unlock()
+		if !sub.closed {
+			sub.err = contextError(ctx, err)
+		}
+	}()
+
+	return sub, nil
+}
+
+// readEvents reads the Server-Sent Events from r, and calls fn with the type and the data of each event
+// while fn returns true.
+func readEvents(r *bufio.Reader, fn func(event, data string) (bool, error)) error {
+	var event string
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read event: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if len(data) > 0 {
+				next, err := fn(event, strings.Join(data, "\n"))
+				if err != nil || !next {
+					return err
+				}
+			}
+			event, data = "", nil
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
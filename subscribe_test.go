@@ -0,0 +1,234 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func sseServer(t *testing.T, events string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "text/event-stream" {
+			t.Errorf("Accept header got %q, want %q", accept, "text/event-stream")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, events)
+	}))
+}
+
+func TestClientSubscribe(t *testing.T) {
+	tests := map[string]struct {
+		events  string
+		methods []string
+		errCode ErrorCode
+	}{
+		"close": {
+			events: "event: data\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"tick\",\"params\":[1]}\n\n" +
+				": comment\n\n" +
+				"event: progress\ndata: 50\n\n" +
+				"data: {\"jsonrpc\":\"2.0\",\"method\":\"tock\",\"params\":[2]}\n\n" +
+				"event: close\ndata: bye\n\n" +
+				"event: data\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"ignored\"}\n\n",
+			methods: []string{"tick", "tock"},
+		},
+		"error": {
+			events: "event: data\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"tick\",\"params\":[1]}\n\n" +
+				"event: error\ndata: {\"code\":-32603,\n" +
+				"data: \"message\":\"internal error\"}\n\n" +
+				"event: data\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"ignored\"}\n\n",
+			methods: []string{"tick"},
+			errCode: InternalError,
+		},
+		"end": {
+			events:  "data: {\"jsonrpc\":\"2.0\",\"method\":\"tick\",\"params\":[1]}\r\n\r\n",
+			methods: []string{"tick"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := sseServer(t, tt.events)
+			defer server.Close()
+
+			client := &Client{}
+
+			sub, err := client.Subscribe(context.Background(), server.URL, "subscribe", nil)
+			if err != nil {
+				t.Fatalf("Client.Subscribe() error: %v", err)
+			}
+
+			var methods []string
+			for n := range sub.C {
+				methods = append(methods, n.Method)
+			}
+			if fmt.Sprint(methods) != fmt.Sprint(tt.methods) {
+				t.Errorf("Subscription.C got %v, want %v", methods, tt.methods)
+			}
+
+			err = sub.Err()
+			if tt.errCode == 0 {
+				if err != nil {
+					t.Errorf("Subscription.Err() got %v, want nil", err)
+				}
+				return
+			}
+			var rpcErr *ResponseError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != tt.errCode {
+				t.Errorf("Subscription.Err() got %v, want %v", err, tt.errCode)
+			}
+		})
+	}
+}
+
+func TestClientSubscribeClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"tick\"}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	sub, err := client.Subscribe(context.Background(), server.URL, "subscribe", nil)
+	if err != nil {
+		t.Fatalf("Client.Subscribe() error: %v", err)
+	}
+
+	if n := <-sub.C; n.Method != "tick" {
+		t.Errorf("Subscription.C got %q, want %q", n.Method, "tick")
+	}
+	sub.Close()
+	for range sub.C {
+	}
+
+	if err := sub.Err(); err != nil {
+		t.Errorf("Subscription.Err() got %v, want nil", err)
+	}
+}
+
+func TestClientSubscribeServerClose(t *testing.T) {
+	tests := map[string]string{
+		"close":  "event: close\ndata: bye\n\n",
+		"error":  "event: error\ndata: {\"code\":-32603,\"message\":\"internal error\"}\n\n",
+		"broken": "data: {\"jsonrpc\"\n\n",
+	}
+
+	for name, events := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the server keeps the connection open after the stream ends.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, events)
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			sub, err := client.Subscribe(context.Background(), server.URL, "subscribe", nil)
+			if err != nil {
+				t.Fatalf("Client.Subscribe() error: %v", err)
+			}
+			defer sub.Close()
+
+			timeout := time.After(time.Second)
+			for closed := false; !closed; {
+				select {
+				case _, ok := <-sub.C:
+					closed = !ok
+				case <-timeout:
+					t.Fatal("Subscription.C is not closed after the server ends the stream")
+				}
+			}
+		})
+	}
+}
+
+func TestClientSubscribeMaxSubscriptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMaxSubscriptions(2))
+
+	var subs []*Subscription
+	for i := 0; i < 2; i++ {
+		sub, err := client.Subscribe(context.Background(), server.URL, "subscribe", nil)
+		if err != nil {
+			t.Fatalf("Client.Subscribe() failed: %v", err)
+		}
+		defer sub.Close()
+		subs = append(subs, sub)
+	}
+
+	if stats := client.StreamStats(); stats.Pending != 2 || stats.Readers != 2 {
+		t.Errorf("Client.StreamStats() got %+v, want 2 pending and 2 readers", stats)
+	}
+
+	_, err := client.Subscribe(context.Background(), server.URL, "subscribe", nil)
+	var pendingErr *TooManyPendingError
+	if !errors.As(err, &pendingErr) || pendingErr.Max != 2 {
+		t.Fatalf("Client.Subscribe() error got %v, want *TooManyPendingError", err)
+	}
+
+	subs[0].Close()
+	for range subs[0].C {
+	}
+	deadline := time.Now().Add(time.Second)
+	for client.StreamStats().Pending != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Client.StreamStats() got %+v after Close, want 1 pending", client.StreamStats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	sub, err := client.Subscribe(context.Background(), server.URL, "subscribe", nil)
+	if err != nil {
+		t.Fatalf("Client.Subscribe() after Close failed: %v", err)
+	}
+	sub.Close()
+}
+
+func TestClientSubscribeMaxSubscriptionsServerClose(t *testing.T) {
+	// the server ends the stream, but keeps the connection open.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: close\ndata: bye\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMaxSubscriptions(1))
+
+	for i := 0; i < 3; i++ {
+		sub, err := client.Subscribe(context.Background(), server.URL, "subscribe", nil)
+		if err != nil {
+			t.Fatalf("Client.Subscribe() %d failed: %v", i, err)
+		}
+
+		timeout := time.After(time.Second)
+		for closed := false; !closed; {
+			select {
+			case _, ok := <-sub.C:
+				closed = !ok
+			case <-timeout:
+				t.Fatalf("Subscription.C %d is not closed after the server closes the stream", i)
+			}
+		}
+
+		if stats := client.StreamStats(); stats.Pending != 0 || stats.Readers != 0 {
+			t.Errorf("Client.StreamStats() got %+v after the server closes the stream %d, want 0 pending and 0 readers", stats, i)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBodyReadTimeout is returned when a read of the response body is blocked
+// longer than the timeout set by WithBodyReadTimeout.
+var ErrBodyReadTimeout = errors.New("timed out reading response body")
+
+// timeoutBody closes rc if a read is blocked longer than timeout.
+type timeoutBody struct {
+	rc       io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut int32
+}
+
+func newTimeoutBody(rc io.ReadCloser, timeout time.Duration) *timeoutBody {
+	b := &timeoutBody{
+		rc:      rc,
+		timeout: timeout,
+	}
+	b.timer = time.AfterFunc(timeout, b.expire)
+	b.timer.Stop()
+
+	return b
+}
+
+func (b *timeoutBody) expire() {
+	atomic.StoreInt32(&b.timedOut, 1)
+	b.rc.Close()
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	if atomic.LoadInt32(&b.timedOut) == 1 {
+		return 0, ErrBodyReadTimeout
+	}
+
+	b.timer.Reset(b.timeout)
+	n, err := b.rc.Read(p)
+	b.timer.Stop()
+
+	if atomic.LoadInt32(&b.timedOut) == 1 {
+		return n, ErrBodyReadTimeout
+	}
+
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	b.timer.Stop()
+	return b.rc.Close()
+}
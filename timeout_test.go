@@ -0,0 +1,80 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// trickleHandler responds a result by writing the body a few bytes at a time with the interval.
+func trickleHandler(interval time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		body, _ := json.Marshal(&testResponse{
+			JSONRPC: Version,
+			Result:  "ok",
+			ID:      req.ID,
+		})
+
+		for len(body) > 0 {
+			n := 8
+			if n > len(body) {
+				n = len(body)
+			}
+			if _, err := w.Write(body[:n]); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			body = body[n:]
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	})
+}
+
+func TestClientCallBodyReadTimeout(t *testing.T) {
+	server := httptest.NewServer(trickleHandler(200 * time.Millisecond))
+	defer server.Close()
+
+	client := &Client{}
+
+	start := time.Now()
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "slow", nil, &result, WithBodyReadTimeout(50*time.Millisecond))
+	if !errors.Is(err, ErrBodyReadTimeout) {
+		t.Fatalf("Client.Call() error got %v, want ErrBodyReadTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Client.Call() took %v, want to abort soon after the stall", elapsed)
+	}
+}
+
+func TestClientCallBodyReadTimeoutReset(t *testing.T) {
+	server := httptest.NewServer(trickleHandler(10 * time.Millisecond))
+	defer server.Close()
+
+	client := &Client{}
+
+	var result string
+	err := client.Call(context.Background(), server.URL, "trickle", nil, &result, WithBodyReadTimeout(200*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Client.Call() failed: %v", err)
+	}
+	if result != "ok" {
+		t.Errorf("Client.Call() result got %q, want %q", result, "ok")
+	}
+}
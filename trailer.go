@@ -0,0 +1,65 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// DefaultErrorTrailer is the HTTP trailer commonly used to report an error after the result is streamed.
+const DefaultErrorTrailer = "Jsonrpc-Error"
+
+// WithErrorTrailer returns an Option that reads the HTTP trailer of the name after the response body of CallStream,
+// and returns the JSON-RPC error object in it as a *ResponseError,
+// for the servers which cannot know whether the call succeeds until the result is streamed.
+// e.g. Jsonrpc-Error: {"code":-32603,"message":"stream failed"}
+//
+// The error in the trailer takes precedence over the error reading a truncated body.
+// If the items are already processed, it is returned as the Err of an *IncompleteResultError.
+// The trailer is not read if fn returns an error.
+func WithErrorTrailer(name string) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ErrorTrailer = name
+	})
+}
+
+// trailerError drains the body of res, and returns the error reported in the ErrorTrailer of res if any.
+func (client *Client) trailerError(res *http.Response, callOpts callOptions) error {
+	io.Copy(ioutil.Discard, res.Body)
+
+	v := res.Trailer.Get(callOpts.ErrorTrailer)
+	if v == "" {
+		return nil
+	}
+
+	var rpcErr ResponseError
+	if err := json.Unmarshal([]byte(v), &rpcErr); err != nil {
+		return fmt.Errorf("failed to decode error trailer: %w", err)
+	}
+	callOpts.mapErrorCode(&rpcErr)
+	client.decodeErrorData(&rpcErr)
+
+	return &rpcErr
+}
+
+// withTrailerError returns the error reported in the trailer of res in place of err,
+// keeping the number of the items processed if err is an *IncompleteResultError.
+func (client *Client) withTrailerError(res *http.Response, err error, callOpts callOptions) error {
+	terr := client.trailerError(res, callOpts)
+	if terr == nil {
+		return err
+	}
+
+	var incomplete *IncompleteResultError
+	if errors.As(err, &incomplete) && incomplete.Items > 0 {
+		return &IncompleteResultError{
+			Items: incomplete.Items,
+			Err:   terr,
+		}
+	}
+
+	return terr
+}
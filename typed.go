@@ -0,0 +1,27 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+)
+
+// CallE calls the method on the url with the params like Client.Call, and returns the result of type R.
+// The errors are separated into the two failure domains:
+// the error responded by the server is returned as the *ResponseError,
+// and the other errors, e.g. transport errors and decode errors, are returned as the error.
+// At most one of them is non-nil.
+func CallE[R any](ctx context.Context, client *Client, url string, method string, params interface{}, opts ...Option) (R, *ResponseError, error) {
+	var result R
+	err := client.Call(ctx, url, method, params, &result, opts...)
+	if err == nil {
+		return result, nil, nil
+	}
+
+	var zero R
+	var rpcErr *ResponseError
+	if errors.As(err, &rpcErr) {
+		return zero, rpcErr, nil
+	}
+
+	return zero, nil, err
+}
@@ -0,0 +1,51 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallE(t *testing.T) {
+	server := httptest.NewServer(rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		if req.Method == "fail" {
+			return nil, &ResponseError{Code: InvalidParams, Message: "invalid params"}
+		}
+		return 42, nil
+	}))
+	defer server.Close()
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	client := &Client{}
+
+	got, rpcErr, err := CallE[int](context.Background(), client, server.URL, "answer", nil)
+	if err != nil || rpcErr != nil {
+		t.Fatalf("CallE() failed: %v, %v", rpcErr, err)
+	}
+	if got != 42 {
+		t.Errorf("CallE() got %d, want 42", got)
+	}
+
+	got, rpcErr, err = CallE[int](context.Background(), client, server.URL, "fail", nil)
+	if err != nil {
+		t.Errorf("CallE() error got %v, want nil for an RPC error", err)
+	}
+	if rpcErr == nil || rpcErr.Code != InvalidParams {
+		t.Errorf("CallE() ResponseError got %v, want InvalidParams", rpcErr)
+	}
+	if got != 0 {
+		t.Errorf("CallE() got %d with an RPC error, want 0", got)
+	}
+
+	_, rpcErr, err = CallE[int](context.Background(), client, unavailable.URL, "answer", nil)
+	if rpcErr != nil {
+		t.Errorf("CallE() ResponseError got %v, want nil for a transport error", rpcErr)
+	}
+	if err == nil {
+		t.Error("CallE() error got nil, want the StatusError")
+	}
+}
@@ -0,0 +1,85 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// WithResponseVerifier returns an Option that calls verify with the full response body and the header
+// before decoding the response, e.g. to verify an HMAC signature of the body sent in a header.
+// The body is the decompressed one if the response is compressed.
+// If verify returns an error, the call fails with the error wrapped as "response verification failed".
+//
+// The response body is read entirely up to MaxRewriteBytes before decoding, so results are not streamed with this option.
+// The call fails with ErrRewriteTooLarge if the body is larger.
+func WithResponseVerifier(verify func(body []byte, header http.Header) error) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ResponseVerifier = verify
+	})
+}
+
+// verifyResponse reads the body of res, and verifies it by the ResponseVerifier.
+// The body of res is replaced with the read one.
+// The original body is read to the end, so that the trailer of res is still received.
+func (opts *callOptions) verifyResponse(res *http.Response) error {
+	if opts.ResponseVerifier == nil {
+		return nil
+	}
+
+	b, err := readRewriteBody(res)
+	if err != nil {
+		return err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	if err := opts.ResponseVerifier(b, res.Header); err != nil {
+		return fmt.Errorf("response verification failed: %w", err)
+	}
+
+	return nil
+}
+
+// ServerMismatchError is returned when the server identity header of the response
+// is not matched to the one expected by WithExpectServerHeader.
+type ServerMismatchError struct {
+	// Header is the name of the server identity header.
+	Header string `json:"header"`
+	// Expected is the expected value of the header.
+	Expected string `json:"expected"`
+	// Got is the value of the header responded, empty if the header is missing.
+	Got string `json:"got"`
+}
+
+func (err *ServerMismatchError) Error() string {
+	return fmt.Sprintf("unexpected server: header %s got %q, want %q", err.Header, err.Got, err.Expected)
+}
+
+// WithExpectServerHeader returns an Option that verifies the header of the response equals expected,
+// e.g. to detect the responses from a wrong origin behind a CDN.
+// It is verified whatever the status of the response is,
+// and the call fails with a ServerMismatchError if the header does not match.
+func WithExpectServerHeader(header, expected string) Option {
+	return optionFunc(func(opts *callOptions) {
+		opts.ExpectServerHeader = header
+		opts.ExpectServerValue = expected
+	})
+}
+
+// checkServerHeader verifies the server identity header of res by the ExpectServerHeader.
+func (opts *callOptions) checkServerHeader(res *http.Response) error {
+	if opts.ExpectServerHeader == "" {
+		return nil
+	}
+
+	if got := res.Header.Get(opts.ExpectServerHeader); got != opts.ExpectServerValue {
+		return &ServerMismatchError{
+			Header:   opts.ExpectServerHeader,
+			Expected: opts.ExpectServerValue,
+			Got:      got,
+		}
+	}
+
+	return nil
+}
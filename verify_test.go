@@ -0,0 +1,187 @@
+package jsonrpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCallWithResponseVerifier(t *testing.T) {
+	key := []byte("secret")
+	sign := func(key, body []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	verifier := WithResponseVerifier(func(body []byte, header http.Header) error {
+		sig, err := hex.DecodeString(header.Get("X-Signature"))
+		if err != nil {
+			return err
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	})
+
+	tests := map[string]struct {
+		key     []byte
+		wantErr bool
+	}{
+		"valid": {
+			key: key,
+		},
+		"invalid": {
+			key:     []byte("other"),
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+				return "signed", nil
+			})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+				body := rec.Body.Bytes()
+				w.Header().Set("X-Signature", sign(tt.key, body))
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			client := &Client{}
+
+			var result string
+			err := client.Call(context.Background(), server.URL, "test", nil, &result, verifier)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "response verification failed") {
+					t.Fatalf("Client.Call() error got %v, want response verification failed", err)
+				}
+				if result != "" {
+					t.Errorf("Client.Call() got %q, want the result not decoded", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if result != "signed" {
+				t.Errorf("Client.Call() got %q, want %q", result, "signed")
+			}
+		})
+	}
+}
+
+func TestClientCallWithExpectServerHeader(t *testing.T) {
+	handler := rpcHandler(t, func(req *testRequest) (interface{}, *ResponseError) {
+		return "ok", nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", strings.TrimPrefix(r.URL.Path, "/"))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	tests := map[string]struct {
+		origin  string
+		wantErr bool
+	}{
+		"matching":    {origin: "origin-a"},
+		"mismatching": {origin: "origin-b", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result string
+			err := client.Call(context.Background(), server.URL+"/"+tt.origin, "echo", nil, &result, WithExpectServerHeader("X-Served-By", "origin-a"))
+			if tt.wantErr {
+				var mismatch *ServerMismatchError
+				if !errors.As(err, &mismatch) {
+					t.Fatalf("Client.Call() error got %v, want *ServerMismatchError", err)
+				}
+				if mismatch.Got != tt.origin {
+					t.Errorf("ServerMismatchError.Got got %q, want %q", mismatch.Got, tt.origin)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Client.Call() failed: %v", err)
+			}
+			if result != "ok" {
+				t.Errorf("Client.Call() got %q, want %q", result, "ok")
+			}
+		})
+	}
+}
+
+func TestClientCallBatchWithResponseVerifierTrailer(t *testing.T) {
+	handler := testBatchHandler(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		handler.ServeHTTP(w, r)
+		w.Header().Set("X-Checksum", "checksum")
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var verified []byte
+	res, err := client.CallBatch(context.Background(), server.URL, []BatchRequest{
+		{Method: "echo", Params: 1},
+		{Method: "echo", Params: 2},
+	}, WithResponseVerifier(func(body []byte, header http.Header) error {
+		verified = body
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Client.CallBatch() failed: %v", err)
+	}
+
+	if len(verified) == 0 {
+		t.Error("ResponseVerifier got an empty body")
+	}
+	if len(res.Responses) != 2 {
+		t.Errorf("BatchResult.Responses got %d responses, want 2", len(res.Responses))
+	}
+	if got := res.Trailer.Get("X-Checksum"); got != "checksum" {
+		t.Errorf("BatchResult.Trailer got X-Checksum %q, want %q", got, "checksum")
+	}
+}
+
+func TestClientCallWithResponseVerifierTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, strings.Repeat("a", MaxRewriteBytes))
+	}))
+	defer server.Close()
+
+	client := &Client{}
+
+	var called bool
+	var result string
+	err := client.Call(context.Background(), server.URL, "test", nil, &result, WithResponseVerifier(func(body []byte, header http.Header) error {
+		called = true
+		return nil
+	}))
+	if !errors.Is(err, ErrRewriteTooLarge) {
+		t.Errorf("Client.Call() error got %v, want %v", err, ErrRewriteTooLarge)
+	}
+	if called {
+		t.Error("ResponseVerifier must not be called with a too large body")
+	}
+}